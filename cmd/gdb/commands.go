@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/myceliums/gdb/dialect"
+	"github.com/myceliums/gdb/export"
+	"github.com/myceliums/gdb/format"
+	"github.com/myceliums/gdb/model"
+	"github.com/myceliums/gdb/templater"
+)
+
+// generate writes the go code of the configuration, with -watch the code is
+// written again whenever the configuration changes
+func generate(args []string) error {
+	fs, cfg := newFlagSet(`generate`, `generate [options] [configfile|directory...]`)
+	pkg := fs.String(`pkg`, `model`, `specifies the package name`)
+	output := fs.String(`o`, `model.gen.go`, `specifies the output`)
+	watching := fs.Bool(`watch`, false, `watches the configuration and generates the code when it changes`)
+	interval := fs.Duration(`interval`, 500*time.Millisecond, `the interval at which the configuration is checked for changes with -watch`)
+	migrating := fs.Bool(`migrate`, false, `migrates the database of -dsn after generating the code with -watch`)
+	destructive := fs.Bool(`allow-destructive`, false, `allows -migrate to apply changes which can remove data, like dropping a column`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *migrating && !*watching {
+		return usageError("-migrate can only be used with -watch, use gdb migrate to migrate the database once")
+	}
+
+	if *destructive && !*migrating {
+		return usageError("-allow-destructive can only be used with -migrate")
+	}
+
+	dia, err := cfg.dialect()
+	if err != nil {
+		return err
+	}
+
+	if *watching {
+		return watch(cfg, *interval, *migrating, *destructive, func(mdl *model.Model) error {
+			return writeCode(*output, *pkg, dia, mdl)
+		})
+	}
+
+	mdl, err := cfg.model()
+	if err != nil {
+		return err
+	}
+
+	return writeCode(*output, *pkg, dia, mdl)
+}
+
+// writeCode writes the go code of the model to the output file
+func writeCode(output, pkg string, dia dialect.Dialect, mdl *model.Model) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	if err := templater.WriteTemplate(f, pkg, dia, *mdl); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
+
+// validate checks if the configuration is valid
+func validate(args []string) error {
+	fs, cfg := newFlagSet(`validate`, `validate [options] [configfile|directory...]`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if _, err := cfg.model(); err != nil {
+		return err
+	}
+
+	fmt.Println(`configuration is valid`)
+	return nil
+}
+
+// formatConfig prints the configuration files in their canonical format,
+// rewrites them with -w or lists the files that aren't formatted with -check
+func formatConfig(args []string) error {
+	fs, cfg := newFlagSet(`fmt`, `fmt [options] [configfile|directory...]`)
+	check := fs.Bool(`check`, false, `lists the files that aren't formatted and exits with 1 when there are any`)
+	write := fs.Bool(`w`, false, `writes the formatted configuration to the files`)
+	order := fs.String(`order`, string(format.Dependency), `the order of the tables, dependency or name`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	files, err := model.Files(cfg.files...)
+	if err != nil {
+		return configError(err)
+	}
+
+	unformatted := 0
+	for _, file := range files {
+		in, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		out, err := format.Source(in, format.Options{Order: format.Order(*order)})
+		if err != nil {
+			return configError(fmt.Errorf("%s: %v", file, err))
+		}
+
+		switch {
+		case *check:
+			if !bytes.Equal(in, out) {
+				fmt.Println(file)
+				unformatted++
+			}
+		case *write:
+			if !bytes.Equal(in, out) {
+				if err := ioutil.WriteFile(file, out, 0644); err != nil {
+					return err
+				}
+			}
+		default:
+			fmt.Print(string(out))
+		}
+	}
+
+	if unformatted > 0 {
+		return &exitError{code: exitFailure, err: fmt.Errorf("%d files aren't formatted", unformatted)}
+	}
+
+	return nil
+}
+
+// diff prints the summary and the sql which migrate the first configuration to the second
+func diff(args []string) error {
+	fs, cfg := newFlagSet(`diff`, `diff [options] <old config> <new config>
+       gdb diff -ref <git revision> [options] <config file>`)
+	ref := fs.String(`ref`, ``, `compares the config file at the git revision with the working copy`)
+	asJSON := fs.Bool(`json`, false, `prints the summary, the changes and the sql as json`)
+	exitCode := fs.Bool(`exit-code`, false, `exits with 5 when there are changes`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if (*ref == `` && fs.NArg() != 2) || (*ref != `` && fs.NArg() != 1) {
+		fs.Usage()
+		return usageError(`diff needs two configurations, or a revision and a configuration`)
+	}
+
+	dia, err := cfg.dialect()
+	if err != nil {
+		return err
+	}
+
+	var prev *model.Model
+	if *ref != `` {
+		snapshot, err := export.FromRevision(fs.Arg(0), *ref)
+		if err != nil {
+			return configError(err)
+		}
+		prev = &snapshot.Model
+	} else if prev, err = model.NewFromFiles(fs.Arg(0)); err != nil {
+		return configError(err)
+	}
+
+	curr, err := model.NewFromFiles(fs.Arg(fs.NArg() - 1))
+	if err != nil {
+		return configError(err)
+	}
+
+	p := model.Diff(*prev, *curr)
+	sq := p.SQL(dia)
+
+	if *asJSON {
+		out := struct {
+			Summary model.Summary  `json:"summary"`
+			Changes []model.Change `json:"changes"`
+			SQL     string         `json:"sql"`
+		}{p.Summary(), p.Changes, sq}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent(``, `  `)
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(p.Summary())
+		if sq != `` {
+			fmt.Print("\n" + sq)
+		}
+	}
+
+	if *exitCode && len(p.Changes) > 0 {
+		return &exitError{code: exitPending}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/myceliums/gdb/model"
+)
+
+// migrate migrates the database to the configuration
+func migrate(args []string) error {
+	fs, cfg := newFlagSet(`migrate`, `migrate [options] [configfile|directory...]`)
+	online := fs.Bool(`online`, false, `avoids long locks on existing tables, see MigrateOnline`)
+	lockTimeout := fs.Duration(`lock-timeout`, 2*time.Second, `the lock timeout of an online migration`)
+	retries := fs.Int(`retries`, 5, `the number of retries after a lock timeout of an online migration`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	mdl, err := cfg.model()
+	if err != nil {
+		return err
+	}
+
+	dia, db, err := cfg.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close() // nolint: errcheck
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := model.Options{Logger: model.StdLogger{}}
+	if *online {
+		opts.Online = &model.OnlineOptions{LockTimeout: *lockTimeout, Retries: *retries, RetryDelay: time.Second}
+	}
+
+	if err := model.MigrateContext(ctx, dia, db, *mdl, opts); err != nil {
+		return databaseError(err)
+	}
+
+	return nil
+}
+
+// plan prints the sql or the changes which migrate the database to the configuration
+func plan(args []string) error {
+	fs, cfg := newFlagSet(`plan`, `plan [options] [configfile|directory...]`)
+	asJSON := fs.Bool(`json`, false, `prints the plan as json`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	mdl, err := cfg.model()
+	if err != nil {
+		return err
+	}
+
+	dia, db, err := cfg.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close() // nolint: errcheck
+
+	_, stored, err := model.Stored(context.Background(), dia, db)
+	if err != nil {
+		return databaseError(err)
+	}
+
+	p := model.Initial(*mdl)
+	if stored != nil {
+		p = model.Diff(*stored, *mdl)
+	}
+
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(p)
+	}
+
+	fmt.Print(p.SQL(dia))
+	return nil
+}
+
+// status prints the version of the database and whether it's migrated to the
+// configuration, it exits with exitPending when there are changes to migrate
+func status(args []string) error {
+	fs, cfg := newFlagSet(`status`, `status [options] [configfile|directory...]`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	mdl, err := cfg.model()
+	if err != nil {
+		return err
+	}
+
+	dia, db, err := cfg.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close() // nolint: errcheck
+
+	version, stored, err := model.Stored(context.Background(), dia, db)
+	if err != nil {
+		return databaseError(err)
+	}
+
+	if stored == nil {
+		fmt.Println(`database is not migrated`)
+		return &exitError{code: exitPending}
+	}
+
+	changes := model.Diff(*stored, *mdl).Changes
+	if len(changes) > 0 {
+		fmt.Printf("database is at version %d, %d changes pending\n", version, len(changes))
+		return &exitError{code: exitPending}
+	}
+
+	fmt.Printf("database is at version %d and up to date\n", version)
+	return nil
+}
+
+// introspect prints the configuration the database is migrated to
+func introspect(args []string) error {
+	fs, cfg := newFlagSet(`introspect`, `introspect [options]`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	dia, db, err := cfg.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close() // nolint: errcheck
+
+	_, stored, err := model.Stored(context.Background(), dia, db)
+	if err != nil {
+		return databaseError(err)
+	}
+
+	if stored == nil {
+		return databaseError(fmt.Errorf("database is not migrated by gdb"))
+	}
+
+	fmt.Print(string(stored.Config()))
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/myceliums/gdb/docs"
+	"github.com/myceliums/gdb/model"
+)
+
+// documentation writes the documentation of the configuration as markdown or html
+func documentation(args []string) error {
+	fs, cfg := newFlagSet(`docs`, `docs [options] [configfile|directory...]`)
+	format := fs.String(`format`, `markdown`, `the format of the documentation, markdown or html`)
+	title := fs.String(`title`, `Database`, `the title of the documentation`)
+	output := fs.String(`o`, ``, `specifies the output file (default is stdout)`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	var write func(io.Writer, string, model.Model) error
+	switch *format {
+	case `markdown`, `md`:
+		write = docs.Markdown
+	case `html`:
+		write = docs.HTML
+	default:
+		return usageError("unknown format %s, want markdown or html", *format)
+	}
+
+	mdl, err := cfg.model()
+	if err != nil {
+		return err
+	}
+
+	if *output == `` {
+		return write(os.Stdout, *title, *mdl)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f, *title, *mdl); err != nil {
+		f.Close() // nolint: errcheck
+		return fmt.Errorf("%s: %v", *output, err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/myceliums/gdb/docs"
+)
+
+// erd writes the entity relationship diagram of the configuration
+func erd(args []string) error {
+	fs, cfg := newFlagSet(`erd`, `erd [options] [configfile|directory...]`)
+	format := fs.String(`format`, string(docs.Mermaid), `the language of the diagram, mermaid, dot or plantuml`)
+	output := fs.String(`o`, ``, `specifies the output file (default is stdout)`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	switch docs.Diagram(*format) {
+	case docs.Mermaid, docs.DOT, docs.PlantUML:
+	default:
+		return usageError("unknown format %s, want mermaid, dot or plantuml", *format)
+	}
+
+	mdl, err := cfg.model()
+	if err != nil {
+		return err
+	}
+
+	if *output == `` {
+		return docs.ERD(os.Stdout, *mdl, docs.Diagram(*format))
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := docs.ERD(f, *mdl, docs.Diagram(*format)); err != nil {
+		f.Close() // nolint: errcheck
+		return fmt.Errorf("%s: %v", *output, err)
+	}
+
+	return f.Close()
+}
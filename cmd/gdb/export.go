@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/myceliums/gdb/export"
+)
+
+// exportMigrations writes the migrations between the versions of a configuration as sql files
+func exportMigrations(args []string) error {
+	fs, cfg := newFlagSet(`export-migrations`, `export-migrations [options] <snapshot directory>
+       gdb export-migrations -git [options] <config file>`)
+	fromGit := fs.Bool(`git`, false, `reads the versions from the git history of the config file`)
+	revisions := fs.String(`revs`, ``, `limits the git revisions, e.g. v1.0..HEAD`)
+	format := fs.String(`format`, string(export.Migrate), `the file format, migrate (golang-migrate) or goose`)
+	output := fs.String(`o`, `migrations`, `specifies the output directory`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return usageError(`export-migrations needs a snapshot directory or a config file`)
+	}
+
+	dia, err := cfg.dialect()
+	if err != nil {
+		return err
+	}
+
+	var snapshots []export.Snapshot
+	if *fromGit {
+		snapshots, err = export.FromGit(fs.Arg(0), *revisions)
+	} else {
+		snapshots, err = export.FromDir(fs.Arg(0))
+	}
+	if err != nil {
+		return configError(err)
+	}
+
+	files, err := export.Write(dia, snapshots, *output, export.Format(*format))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		fmt.Println(f)
+	}
+
+	return nil
+}
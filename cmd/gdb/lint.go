@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/myceliums/gdb/lint"
+)
+
+// defaultLintConfig is the rule configuration that's read when it exists and -rules isn't given
+const defaultLintConfig = `.gdblint.yml`
+
+// lintConfig checks the configuration for design mistakes, it exits with
+// exitLint when an error is found
+func lintConfig(args []string) error {
+	fs, cfg := newFlagSet(`lint`, `lint [options] [configfile|directory...]`)
+	rules := fs.String(`rules`, ``, `the rule configuration file (default is `+defaultLintConfig+` when it exists)`)
+	disable := fs.String(`disable`, ``, `the rules to disable, comma separated`)
+	asJSON := fs.Bool(`json`, false, `prints the diagnostics as json`)
+	list := fs.Bool(`list`, false, `lists the rules`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *list {
+		for _, rule := range lint.Rules() {
+			fmt.Printf("%-20s %-8s %s\n", rule.Name, rule.Severity, rule.Description)
+		}
+		return nil
+	}
+
+	var conf lint.Config
+	if _, err := os.Stat(defaultLintConfig); *rules == `` && err == nil {
+		*rules = defaultLintConfig
+	}
+	if *rules != `` {
+		c, err := lint.ReadConfig(*rules)
+		if err != nil {
+			return usageError("%v", err)
+		}
+		conf = c
+	}
+
+	for _, name := range strings.Split(*disable, `,`) {
+		if name = strings.TrimSpace(name); name != `` {
+			conf.Disable = append(conf.Disable, name)
+		}
+	}
+
+	if err := conf.Validate(); err != nil {
+		return usageError("%v", err)
+	}
+
+	mdl, err := cfg.model()
+	if err != nil {
+		return err
+	}
+
+	positions, err := lint.PositionsFromFiles(cfg.files...)
+	if err != nil {
+		return configError(err)
+	}
+
+	diagnostics := lint.Lint(*mdl, conf, positions)
+
+	if *asJSON {
+		if diagnostics == nil {
+			diagnostics = []lint.Diagnostic{}
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent(``, `  `)
+		if err := enc.Encode(diagnostics); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range diagnostics {
+			fmt.Println(d)
+		}
+	}
+
+	if lint.HasErrors(diagnostics) {
+		return &exitError{code: exitLint}
+	}
+
+	return nil
+}
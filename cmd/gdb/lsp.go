@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+
+	"github.com/myceliums/gdb/lsp"
+)
+
+// languageServer serves the language server protocol over stdin and stdout
+func languageServer(args []string) error {
+	fs, cfg := newFlagSet(`lsp`, `lsp [options]`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	dia, err := cfg.dialect()
+	if err != nil {
+		return err
+	}
+
+	return lsp.NewServer(os.Stdin, os.Stdout, dia).Serve()
+}
@@ -1,63 +1,129 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"strings"
+)
 
-	"github.com/myceliums/gdb/model"
-	"github.com/myceliums/gdb/templater"
+// The exit codes of gdb
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitConfig   = 3
+	exitDatabase = 4
+	exitPending  = 5
+	exitLint     = 6
 )
 
-type Config struct {
-	Pkg    string
-	Config []byte
-	Output string
+// command is a subcommand of gdb
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{`generate`, `generates the go code of the configuration`, generate},
+	{`migrate`, `migrates the database to the configuration`, migrate},
+	{`plan`, `prints the sql which migrates the database to the configuration`, plan},
+	{`status`, `prints the migration status of the database`, status},
+	{`diff`, `prints the sql between two configurations`, diff},
+	{`introspect`, `prints the configuration the database is migrated to`, introspect},
+	{`validate`, `validates the configuration`, validate},
+	{`lint`, `checks the configuration for design mistakes`, lintConfig},
+	{`fmt`, `prints the configuration in its canonical format`, formatConfig},
+	{`docs`, `writes the documentation of the configuration as markdown or html`, documentation},
+	{`erd`, `writes the entity relationship diagram as mermaid, dot or plantuml`, erd},
+	{`schema`, `prints the JSON Schema of the configuration for editors`, schema},
+	{`lsp`, `serves the language server protocol over stdin and stdout for editors`, languageServer},
+	{`export-migrations`, `writes the migrations between versions of the configuration as sql files`, exportMigrations},
 }
 
-func NewConfig() *Config {
-	x := &Config{}
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	flag.StringVar(&x.Pkg, `pkg`, `model`, `specifies the package name`)
-	flag.StringVar(&x.Output, `o`, `model.gen.go`, `specifies the output`)
+// run runs the command of the arguments and returns the exit code, without a
+// known command the arguments are passed to generate
+func run(args []string) int {
+	name, cmd := `generate`, commands[0]
+	if len(args) > 0 {
+		switch args[0] {
+		case `help`, `-h`, `-help`, `--help`:
+			usage()
+			return exitOK
+		}
+
+		for _, c := range commands {
+			if c.name == args[0] {
+				name, cmd, args = c.name, c, args[1:]
+				break
+			}
+		}
+	}
 
-	if !flag.Parsed() {
-		flag.Parse()
+	err := cmd.run(args)
+	if err == nil {
+		return exitOK
 	}
 
-	if len(flag.Args()) < 1 {
-		fmt.Println("to few arguments")
-		os.Exit(1)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
 	}
 
-	cfg := flag.Args()[0]
-	btz, err := ioutil.ReadFile(cfg)
-	errExit(err, `error reading config file`)
+	var exit *exitError
+	if !errors.As(err, &exit) {
+		exit = &exitError{code: exitFailure, err: err}
+	}
 
-	x.Config = btz
+	if exit.err != nil {
+		fmt.Fprintf(os.Stderr, "gdb %s: %v\n", name, exit.err) // nolint: errcheck
+	}
 
-	return x
+	return exit.code
 }
 
-func main() {
-	cfg := NewConfig()
+func usage() {
+	builder := &strings.Builder{}
+	builder.WriteString("usage: gdb <command> [options] [configfile|directory...]\n\nCommands:\n") // nolint: errcheck
+	for _, c := range commands {
+		fmt.Fprintf(builder, "  %-18s %s\n", c.name, c.summary) // nolint: errcheck
+	}
+	builder.WriteString("\nRun gdb <command> -h for the options of a command, without a command gdb generates.\n") // nolint: errcheck
 
-	mdl, err := model.New(cfg.Config)
-	errExit(err, `error reading this config`)
+	fmt.Fprint(os.Stderr, builder.String()) // nolint: errcheck
+}
 
-	f, err := os.OpenFile(cfg.Output, os.O_WRONLY|os.O_CREATE, 0644)
-	errExit(err, `error writing file`)
+// exitError is an error with the exit code of gdb
+type exitError struct {
+	code int
+	err  error
+}
 
-	errExit(templater.WriteTemplate(f, cfg.Pkg, *mdl), `error writing file`)
+func (x *exitError) Error() string {
+	if x.err == nil {
+		return fmt.Sprintf("exit code %d", x.code)
+	}
 
-	errExit(f.Close(), `error writing file`)
+	return x.err.Error()
 }
 
-func errExit(err error, msg ...string) {
-	if err != nil {
-		fmt.Println(strings.Join(msg, ` `))
-		os.Exit(1)
-	}
+func (x *exitError) Unwrap() error {
+	return x.err
+}
+
+func usageError(format string, args ...interface{}) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func configError(err error) error {
+	return &exitError{code: exitConfig, err: err}
+}
+
+func databaseError(err error) error {
+	return &exitError{code: exitDatabase, err: err}
 }
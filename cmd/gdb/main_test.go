@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myceliums/assert"
+)
+
+func TestRunExitCodes(t *testing.T) {
+	as := assert.New(t)
+
+	dir, err := ioutil.TempDir(``, `gdb-cmd`)
+	as.NoError(err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	valid, invalid := filepath.Join(dir, `valid.yml`), filepath.Join(dir, `invalid.yml`)
+	as.NoError(ioutil.WriteFile(valid, []byte("accounts:\n  id: int primary\n"), 0644))
+	as.NoError(ioutil.WriteFile(invalid, []byte("accounts:\n  id: nope\n"), 0644))
+
+	as.Eq(exitOK, run([]string{`validate`, valid}))
+	as.Eq(exitConfig, run([]string{`validate`, invalid}))
+	as.Eq(exitUsage, run([]string{`validate`, `-unknown`}))
+	as.Eq(exitUsage, run([]string{`status`, `-dsn`, ``, valid}))
+	as.Eq(exitUsage, run([]string{`generate`, `-watch`, `-migrate`, `-dsn`, ``, valid}))
+	as.Eq(exitUsage, run([]string{`generate`, `-migrate`, valid}))
+	as.Eq(exitUsage, run([]string{`generate`, `-watch`, `-allow-destructive`, valid}))
+	as.Eq(exitUsage, run([]string{`diff`, valid}))
+	as.Eq(exitOK, run([]string{`lint`, valid}))
+	as.Eq(exitUsage, run([]string{`lint`, `-disable`, `unknown`, valid}))
+
+	keyless := filepath.Join(dir, `keyless.yml`)
+	as.NoError(ioutil.WriteFile(keyless, []byte("accounts:\n  name: text\n"), 0644))
+	as.Eq(exitLint, run([]string{`lint`, keyless}))
+	as.Eq(exitOK, run([]string{`lint`, `-disable`, `no-primary-key`, keyless}))
+
+	messy := filepath.Join(dir, `messy.yml`)
+	as.NoError(ioutil.WriteFile(messy, []byte("accounts:\n  id: int primary key\n  name: text notnull\n"), 0644))
+	as.Eq(exitFailure, run([]string{`fmt`, `-check`, messy}))
+	as.Eq(exitOK, run([]string{`fmt`, `-w`, messy}))
+	as.Eq(exitOK, run([]string{`fmt`, `-check`, messy}))
+
+	// the included files are formatted with the file that includes them
+	including, included := filepath.Join(dir, `including.yml`), filepath.Join(dir, `included.yml`)
+	as.NoError(ioutil.WriteFile(including, []byte("include: included.yml\n"), 0644))
+	as.NoError(ioutil.WriteFile(included, []byte("roles:\n  id: int primary key\n"), 0644))
+	as.Eq(exitOK, run([]string{`fmt`, `-w`, including}))
+	as.Eq(exitOK, run([]string{`fmt`, `-check`, included}))
+
+	os.Setenv(envConfig, invalid) // nolint: errcheck
+	defer os.Unsetenv(envConfig)  // nolint: errcheck
+	as.Eq(exitConfig, run([]string{`validate`}))
+	as.Eq(exitOK, run([]string{`validate`, `-config`, valid}))
+}
+
+func TestWatcher(t *testing.T) {
+	as := assert.New(t)
+
+	dir, err := ioutil.TempDir(``, `gdb-watch`)
+	as.NoError(err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	main, billing := filepath.Join(dir, `main.yml`), filepath.Join(dir, `billing`, `invoices.yml`)
+	as.NoError(os.Mkdir(filepath.Join(dir, `billing`), 0755))
+	as.NoError(ioutil.WriteFile(main, []byte("include: billing\naccounts:\n  id: int primary\n"), 0644))
+	as.NoError(ioutil.WriteFile(billing, []byte("invoices:\n  id: int primary\n"), 0644))
+
+	w := &watcher{paths: []string{main}}
+	as.True(w.changed())
+	as.False(w.changed())
+
+	as.NoError(ioutil.WriteFile(billing, []byte("invoices:\n  id: int primary\n  total: int\n"), 0644))
+	as.True(w.changed())
+	as.False(w.changed())
+
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, `billing`, `currency.yml`), []byte("currency:\n- eur\n"), 0644))
+	as.True(w.changed())
+
+	as.NoError(os.Remove(billing))
+	as.True(w.changed())
+	as.False(w.changed())
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/myceliums/gdb/model"
+)
+
+// schema prints the JSON Schema of the configuration
+func schema(args []string) error {
+	fs, cfg := newFlagSet(`schema`, `schema [options]`)
+	output := fs.String(`o`, ``, `specifies the output file (default is stdout)`)
+	if err := cfg.parse(fs, args); err != nil {
+		return err
+	}
+
+	out, err := model.JSONSchema()
+	if err != nil {
+		return err
+	}
+
+	if *output != `` {
+		return ioutil.WriteFile(*output, out, 0644)
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
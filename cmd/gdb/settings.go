@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	_ "github.com/lib/pq"
+
+	"github.com/myceliums/gdb/dialect"
+	"github.com/myceliums/gdb/model"
+)
+
+// The environment variables of the shared flags
+const (
+	envConfig = `GDB_CONFIG`
+	envDriver = `GDB_DRIVER`
+	envDSN    = `GDB_DSN`
+)
+
+// settings are the flags shared by the commands
+type settings struct {
+	config string
+	driver string
+	dsn    string
+	files  []string
+}
+
+// newFlagSet returns the flag set of a command with the shared flags
+func newFlagSet(name, usage string) (*flag.FlagSet, *settings) {
+	x := &settings{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: gdb %s\n\nOptions:\n", usage) // nolint: errcheck
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&x.config, `config`, env(envConfig, `db.yml`), `the configuration files and directories, comma separated ($`+envConfig+`)`)
+	fs.StringVar(&x.driver, `driver`, env(envDriver, `postgres`), `the database driver ($`+envDriver+`)`)
+	fs.StringVar(&x.dsn, `dsn`, env(envDSN, ``), `the connection string of the database ($`+envDSN+`)`)
+
+	return fs, x
+}
+
+// parse parses the arguments, the configuration files given as arguments
+// take precedence over the config flag
+func (x *settings) parse(fs *flag.FlagSet, args []string) error {
+	fs.SetOutput(ioutil.Discard)
+	err := fs.Parse(args)
+	fs.SetOutput(os.Stderr)
+
+	switch {
+	case err == flag.ErrHelp:
+		fs.Usage()
+		return err
+	case err != nil:
+		fs.Usage()
+		return usageError("%v", err)
+	}
+
+	x.files = fs.Args()
+	if len(x.files) < 1 {
+		for _, f := range strings.Split(x.config, `,`) {
+			if f = strings.TrimSpace(f); f != `` {
+				x.files = append(x.files, f)
+			}
+		}
+	}
+
+	return nil
+}
+
+// model returns the model of the configuration files
+func (x *settings) model() (*model.Model, error) {
+	if len(x.files) < 1 {
+		return nil, usageError(`no configuration given`)
+	}
+
+	mdl, err := model.NewFromFiles(x.files...)
+	if err != nil {
+		return nil, configError(err)
+	}
+
+	return mdl, nil
+}
+
+// dialect returns the dialect of the driver
+func (x *settings) dialect() (dialect.Dialect, error) {
+	dia := dialect.GetByDriver(x.driver)
+	if dia == nil {
+		return nil, usageError("unsupported driver %s", x.driver)
+	}
+
+	return dia, nil
+}
+
+// open opens the database and checks the connection
+func (x *settings) open() (dialect.Dialect, *sql.DB, error) {
+	dia, err := x.dialect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if x.dsn == `` {
+		return nil, nil, usageError("no connection string given, use -dsn or $%s", envDSN)
+	}
+
+	db, err := sql.Open(x.driver, x.dsn)
+	if err != nil {
+		return nil, nil, databaseError(err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close() // nolint: errcheck
+		return nil, nil, databaseError(err)
+	}
+
+	return dia, db, nil
+}
+
+// env returns the value of the environment variable or the default when it's empty
+func env(key, def string) string {
+	if v := os.Getenv(key); v != `` {
+		return v
+	}
+
+	return def
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/myceliums/gdb/dialect"
+	"github.com/myceliums/gdb/model"
+)
+
+// watch calls generate with the model of the configuration and migrates the database
+// when migrating is set, every time the configuration files change until gdb is
+// interrupted. An invalid configuration is reported and watched until it's fixed.
+// A migration which can remove data is only applied when destructive is set.
+func watch(cfg *settings, interval time.Duration, migrating, destructive bool, generate func(mdl *model.Model) error) error {
+	var dia dialect.Dialect
+	var db *sql.DB
+	if migrating {
+		var err error
+		if dia, db, err = cfg.open(); err != nil {
+			return err
+		}
+		defer db.Close() // nolint: errcheck
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	w := &watcher{paths: cfg.files}
+	w.changed()
+
+	for {
+		rebuild(ctx, cfg, dia, db, destructive, generate)
+
+		for changed := false; !changed; changed = w.changed() {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// rebuild generates the code of the configuration and migrates the database when it's given,
+// the errors are printed as the watch continues
+func rebuild(ctx context.Context, cfg *settings, dia dialect.Dialect, db *sql.DB, destructive bool, generate func(mdl *model.Model) error) {
+	now := time.Now().Format(`15:04:05`)
+
+	mdl, err := cfg.model()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s invalid configuration: %v\n", now, err) // nolint: errcheck
+		return
+	}
+
+	if err := generate(mdl); err != nil {
+		fmt.Fprintf(os.Stderr, "%s generating failed: %v\n", now, err) // nolint: errcheck
+		return
+	}
+	fmt.Printf("%s generated the code\n", now)
+
+	if db == nil {
+		return
+	}
+
+	_, stored, err := model.Stored(ctx, dia, db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s reading the migrated configuration failed: %v\n", now, err) // nolint: errcheck
+		return
+	}
+
+	p := model.Initial(*mdl)
+	if stored != nil {
+		p = model.Diff(*stored, *mdl)
+	}
+
+	if len(p.Changes) == 0 {
+		fmt.Printf("%s the database is up to date\n", now)
+		return
+	}
+
+	fmt.Print(p.Summary())
+	fmt.Print("\n" + p.SQL(dia))
+
+	// the stored configuration can't skip a part of the migration, so the whole migration waits
+	if p.Destructive() && !destructive {
+		fmt.Fprintf(os.Stderr, "%s not migrating, the changes can remove data, use -allow-destructive or gdb migrate\n", now) // nolint: errcheck
+		return
+	}
+
+	if err := model.MigrateContext(ctx, dia, db, *mdl, model.Options{}); err != nil {
+		fmt.Fprintf(os.Stderr, "%s migrating failed: %v\n", now, err) // nolint: errcheck
+		return
+	}
+	fmt.Printf("%s migrated the database\n", now)
+}
+
+// stamp is the modification time and size of a watched file
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watcher polls the configuration files and the files they include for changes,
+// which doesn't need a file notification api of the operating system
+type watcher struct {
+	paths  []string
+	stamps map[string]stamp
+}
+
+// changed returns true when a file was changed, added or removed since the last call
+func (x *watcher) changed() bool {
+	stamps := map[string]stamp{}
+	// the files up to an invalid file are watched until it's fixed
+	files, _ := model.Files(x.paths...)
+	for _, path := range files {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = stamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	changed := len(stamps) != len(x.stamps)
+	for path, s := range stamps {
+		prev, ok := x.stamps[path]
+		changed = changed || !ok || !prev.modTime.Equal(s.modTime) || prev.size != s.size
+	}
+
+	x.stamps = stamps
+	return changed
+}
@@ -1,37 +1,71 @@
 package dialect
 
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/lib/pq"
+)
+
 // Dialect is a parser that transforms the given arguments
 // of its functions into an SQL statement of the given dialect
 type Dialect interface {
 	Type(name string, size int) string
+	QuoteIdentifier(name string) string
+	QuoteLiteral(value string) string
+	AddSchema(name string) string
+	DropSchema(name string) string
 	AddTable(name string, ifnotexists bool) string
 	DropTable(name string) string
 	AddColumn(table, column, typename string, size int) string
-	UpdateColumn(table, colum, typename string, size int) string
+	UpdateColumn(table, colum, typename string, size int, using string) string
+	Cast(column, from, to string) (using string, ok bool)
 	DropColumn(table, column string) string
 	AddPrimaryKey(table string, column []string) string
-	UpdatePrimaryKey(table string, column []string) string
 	DropPrimaryKey(table string) string
 	AddForeignKey(table, columnName, referenceTable, referenceColumn string) string
-	UpdateForeignKey(table, columnName, referenceTable, refrenceColumn string) string
 	DropForeignKey(table, columnName string) string
 	AddUnique(id, table string, column []string) string
-	UpdateUnique(id, table string, column []string) string
 	DropUnique(id, table string) string
 	SetNotNull(table, column string) string
 	DeleteNotNull(table, column string) string
 	AddCheck(table, column, check string) string
-	UpdateCheck(table, column, check string) string
 	DropCheck(table, column string) string
 	AddEnum(name string, values []string) string
 	AppendEnum(name, values string) string
+	InsertEnum(name, value, before string) string
+	RenameEnum(name, to string) string
 	DropEnum(name string) string
 	SetDefault(table, column, value string) string
+	Backfill(table, column, value string) string
+	ZeroValue(typename string) string
 	DropDefault(table, column string) string
-	SetAutoIncrement(table, column string) string
-	UnsetAutoIncrement(table, column string) string
+	SetAutoIncrement(table, column string) []string
+	UnsetAutoIncrement(table, column string) []string
+	ResetAutoIncrement(table, column string) string
+	AddView(name, query string, materialized bool) string
+	DropView(name string, materialized bool) string
+	UpsertRow(table string, keys, columns []string, values []*string) string
+	DeleteRow(table string, keys []string, values []*string) string
+
+	AddForeignKeyNotValid(table, columnName, referenceTable, referenceColumn string) string
+	ValidateForeignKey(table, columnName string) string
+	AddCheckNotValid(table, column, check string) string
+	ValidateCheck(table, column string) string
+	AddNotNullCheck(table, column string) string
+	ValidateNotNullCheck(table, column string) string
+	DropNotNullCheck(table, column string) string
+	AddPrimaryKeyIndex(table string, column []string) string
+	DropPrimaryKeyIndex(table string) string
+	AddPrimaryKeyUsingIndex(table string) string
+	AddUniqueIndex(id, table string, column []string) string
+	DropUniqueIndex(id, table string) string
+	AddUniqueUsingIndex(id, table string) string
+	SetTimeouts(lock, statement time.Duration, local bool) string
+	IsLockTimeout(err error) bool
 
 	AddVersionTable() string
+	HasVersionTable() string
 	CheckVersion() string
 	InsertVersion() string
 
@@ -50,3 +84,14 @@ func GetByDriver(driver string) Dialect {
 
 	return nil
 }
+
+// GetBySQLDriver returns the dialect of the driver of an opened database,
+// or nil when the driver isn't known
+func GetBySQLDriver(drv driver.Driver) Dialect {
+	switch drv.(type) {
+	case *pq.Driver:
+		return new(Postgres)
+	}
+
+	return nil
+}
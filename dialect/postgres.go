@@ -1,8 +1,12 @@
 package dialect
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type Postgres string
@@ -13,6 +17,8 @@ func (x Postgres) Type(name string, size int) string {
 		name = `VARCHAR`
 	case `int`, `smallint`, `bigint`, `float`, `timestamp`, `boolean`, `double`, `text`:
 		name = strings.ToUpper(name)
+	default:
+		return x.QuoteIdentifier(name)
 	}
 	i := name
 	if (name == `VARCHAR` || name == `INT`) && size > 0 {
@@ -22,126 +28,333 @@ func (x Postgres) Type(name string, size int) string {
 	return i
 }
 
+// QuoteIdentifier quotes every part of the (schema qualified) identifier
+func (x Postgres) QuoteIdentifier(name string) string {
+	parts := strings.Split(name, `.`)
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+
+	return strings.Join(parts, `.`)
+}
+
+// QuoteLiteral quotes the value as a string literal
+func (x Postgres) QuoteLiteral(value string) string {
+	return `'` + strings.ReplaceAll(value, `'`, `''`) + `'`
+}
+
+func (x Postgres) AddSchema(name string) string {
+	return fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;\n", x.QuoteIdentifier(name))
+}
+
+func (x Postgres) DropSchema(name string) string {
+	return fmt.Sprintf("DROP SCHEMA %s;\n", x.QuoteIdentifier(name))
+}
+
 func (x Postgres) AddTable(name string, ifnotexists bool) string {
 	i := "CREATE TABLE "
 	if ifnotexists {
 		i += "IF NOT EXISTS "
 	}
 	i += "%s();\n"
-	return fmt.Sprintf(i, name)
+	return fmt.Sprintf(i, x.QuoteIdentifier(name))
 }
 
 func (x Postgres) DropTable(name string) string {
-	return fmt.Sprintf("DROP TABLE %s CASCADE;\n", name)
+	return fmt.Sprintf("DROP TABLE %s CASCADE;\n", x.QuoteIdentifier(name))
 }
 
 func (x Postgres) AddColumn(table, column, typename string, size int) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;\n", table, column, x.Type(typename, size))
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column), x.Type(typename, size))
 }
 
-func (x Postgres) UpdateColumn(table, column, typename string, size int) string {
-	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s;\n", table, column, x.Type(typename, size))
+// UpdateColumn changes the type of the column, the using expression is used
+// to convert the existing values when given
+func (x Postgres) UpdateColumn(table, column, typename string, size int, using string) string {
+	if using != `` {
+		using = ` USING ` + using
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s%s;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column), x.Type(typename, size), using)
 }
 
-func (x Postgres) DropColumn(table, column string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", table, column)
+// Cast returns the expression that converts the column from one type to the other,
+// the expression is empty when postgres converts the values itself. Conversions that
+// might fail on the stored values, like text to int, are not ok and need an explicit expression.
+func (x Postgres) Cast(column, from, to string) (using string, ok bool) {
+	numeric := map[string]bool{`int`: true, `smallint`: true, `bigint`: true, `float`: true, `double`: true}
+	text := map[string]bool{`varchar`: true, `string`: true, `text`: true}
+	primitive := func(name string) bool {
+		return numeric[name] || text[name] || name == `boolean` || name == `timestamp`
+	}
+
+	switch {
+	case from == to, text[to], numeric[from] && numeric[to]:
+		return ``, true
+	case from == `boolean` && to == `int`, from == `int` && to == `boolean`:
+		return fmt.Sprintf("%s::%s", x.QuoteIdentifier(column), x.Type(to, 0)), true
+	case !primitive(to) && (text[from] || !primitive(from)):
+		return fmt.Sprintf("%s::text::%s", x.QuoteIdentifier(column), x.Type(to, 0)), true
+	}
+
+	return ``, false
 }
 
-func (x Postgres) AddPrimaryKey(table string, columns []string) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT pk_%s PRIMARY KEY(%s);\n", table, table, strings.Join(columns, `, `))
+func (x Postgres) DropColumn(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column))
 }
 
-func (x Postgres) UpdatePrimaryKey(table string, columns []string) string {
-	return x.DropPrimaryKey(table) + x.AddPrimaryKey(table, columns)
+func (x Postgres) AddPrimaryKey(table string, columns []string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY(%s);\n", x.QuoteIdentifier(table), x.constraint(`pk`, table), x.list(columns))
 }
 
 func (x Postgres) DropPrimaryKey(table string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT pk_%s;\n", table, table)
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.constraint(`pk`, table))
 }
 
 func (x Postgres) AddForeignKey(table, column, referenceTable, referenceColumn string) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT fk_%s_%s FOREIGN KEY (%s) REFERENCES %s(%s);\n", table, table, column, column, referenceTable, referenceColumn)
-}
-
-func (x Postgres) UpdateForeignKey(table, column, refrerenceTable, referenceColumn string) string {
-	return x.DropForeignKey(table, column) + x.AddForeignKey(table, column, refrerenceTable, referenceColumn)
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s);\n", x.QuoteIdentifier(table), x.constraint(`fk`, table, column), x.QuoteIdentifier(column), x.QuoteIdentifier(referenceTable), x.QuoteIdentifier(referenceColumn))
 }
 
 func (x Postgres) DropForeignKey(table, column string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT fk_%s_%s;\n", table, table, column)
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.constraint(`fk`, table, column))
 }
 
 func (x Postgres) AddUnique(id, table string, columns []string) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT uq_%s UNIQUE(%s);\n", table, id, strings.Join(columns, `, `))
-}
-
-func (x Postgres) UpdateUnique(id, table string, columns []string) string {
-	return x.DropUnique(id, table) + x.AddUnique(id, table, columns)
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE(%s);\n", x.QuoteIdentifier(table), x.QuoteIdentifier(`uq_`+id), x.list(columns))
 }
 
 func (x Postgres) DropUnique(id, table string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP uq_%s;\n", table, id)
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(`uq_`+id))
 }
 
 func (x Postgres) SetNotNull(table, column string) string {
-	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;\n", table, column)
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column))
 }
 
 func (x Postgres) DeleteNotNull(table, column string) string {
-	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;\n", table, column)
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column))
 }
 
 func (x Postgres) AddCheck(table, column, check string) string {
-	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT ch_%s_%s CHECK(%s);\n", table, table, column, check)
-}
-
-func (x Postgres) UpdateCheck(table, column, check string) string {
-	return x.DropCheck(table, column) + x.AddCheck(table, column, check)
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK(%s);\n", x.QuoteIdentifier(table), x.constraint(`ch`, table, column), check)
 }
 
 func (x Postgres) DropCheck(table, column string) string {
-	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT ch_%s_%s;\n", table, table, column)
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.constraint(`ch`, table, column))
 }
 
 func (x Postgres) AddEnum(name string, values []string) string {
-	return fmt.Sprintf("CREATE TYPE %s AS ENUM ('%s');\n", name, strings.Join(values, `', '`))
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = x.QuoteLiteral(value)
+	}
+
+	return fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);\n", x.QuoteIdentifier(name), strings.Join(literals, `, `))
 }
 
+// AppendEnum adds the value to the end of the enum, adding an existing value is
+// a no-op so a migration of which only the new values were committed can be retried
 func (x Postgres) AppendEnum(name, value string) string {
-	return fmt.Sprintf("ALTER TYPE %s ADD VALUE '%s';\n", name, value)
+	return fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s;\n", x.QuoteIdentifier(name), x.QuoteLiteral(value))
+}
+
+func (x Postgres) InsertEnum(name, value, before string) string {
+	return fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s BEFORE %s;\n", x.QuoteIdentifier(name), x.QuoteLiteral(value), x.QuoteLiteral(before))
+}
+
+// RenameEnum renames the enum, the new name stays in the schema of the enum
+func (x Postgres) RenameEnum(name, to string) string {
+	return fmt.Sprintf("ALTER TYPE %s RENAME TO %s;\n", x.QuoteIdentifier(name), x.QuoteIdentifier(to))
 }
 
 func (x Postgres) DropEnum(name string) string {
-	return fmt.Sprintf("DROP TYPE %s;\n", name)
+	return fmt.Sprintf("DROP TYPE %s;\n", x.QuoteIdentifier(name))
 }
 
 func (x Postgres) SetDefault(table, column, value string) string {
-	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;\n", table, column, value)
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column), value)
 }
 
 func (x Postgres) DropDefault(table, column string) string {
-	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;\n", table, column)
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column))
+}
+
+// Backfill sets the value of the rows where the column is null, DEFAULT can be
+// given to fill the column with its default or next sequence value
+func (x Postgres) Backfill(table, column, value string) string {
+	return fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column), value, x.QuoteIdentifier(column))
+}
+
+// ZeroValue returns the zero value of the given primitive type,
+// an empty string is returned for types without a zero value
+func (x Postgres) ZeroValue(typename string) string {
+	switch typename {
+	case `int`, `smallint`, `bigint`, `float`, `double`:
+		return `0`
+	case `boolean`:
+		return `false`
+	case `varchar`, `string`, `text`:
+		return `''`
+	case `timestamp`:
+		return `'epoch'`
+	}
+
+	return ``
+}
+
+// SetAutoIncrement returns the statements which create the sequence of the column,
+// set it to the highest value of the column and use it as default of the column. The
+// sequence is owned by the column, so it's dropped with the column, its table or its schema.
+func (x Postgres) SetAutoIncrement(table, column string) []string {
+	seq := x.QuoteIdentifier(sequence(table, column))
+
+	return []string{
+		fmt.Sprintf("CREATE SEQUENCE %s OWNED BY %s.%s;\n", seq, x.QuoteIdentifier(table), x.QuoteIdentifier(column)),
+		x.ResetAutoIncrement(table, column),
+		fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT nextval(%s::regclass);\n", x.QuoteIdentifier(table), x.QuoteIdentifier(column), x.QuoteLiteral(seq)),
+	}
+}
+
+// ResetAutoIncrement returns the statement which sets the sequence of the column to the highest
+// value of the column, so the next value doesn't collide with a row that was inserted with its value
+func (x Postgres) ResetAutoIncrement(table, column string) string {
+	seq := x.QuoteIdentifier(sequence(table, column))
+	return fmt.Sprintf("SELECT setval(%s, (SELECT max(%s) FROM %s));\n", x.QuoteLiteral(seq), x.QuoteIdentifier(column), x.QuoteIdentifier(table))
+}
+
+// UnsetAutoIncrement returns the statements which drop the default and the sequence of the column
+func (x Postgres) UnsetAutoIncrement(table, column string) []string {
+	return []string{
+		x.DropDefault(table, column),
+		fmt.Sprintf("DROP SEQUENCE %s CASCADE;\n", x.QuoteIdentifier(sequence(table, column))),
+	}
+}
+
+func (x Postgres) AddView(name, query string, materialized bool) string {
+	if materialized {
+		return fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS %s;\n", x.QuoteIdentifier(name), query)
+	}
+
+	return fmt.Sprintf("CREATE VIEW %s AS %s;\n", x.QuoteIdentifier(name), query)
+}
+
+func (x Postgres) DropView(name string, materialized bool) string {
+	if materialized {
+		return fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s;\n", x.QuoteIdentifier(name))
+	}
+
+	return fmt.Sprintf("DROP VIEW IF EXISTS %s;\n", x.QuoteIdentifier(name))
+}
+
+func (x Postgres) UpsertRow(table string, keys, columns []string, values []*string) string {
+	var updates []string
+	for _, col := range columns {
+		if !contains(keys, col) {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", x.QuoteIdentifier(col), x.QuoteIdentifier(col)))
+		}
+	}
+
+	action := `DO NOTHING`
+	if len(updates) > 0 {
+		action = `DO UPDATE SET ` + strings.Join(updates, `, `)
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) %s;\n", x.QuoteIdentifier(table), x.list(columns), x.values(values), x.list(keys), action)
+}
+
+func (x Postgres) DeleteRow(table string, keys []string, values []*string) string {
+	conds := make([]string, len(keys))
+	for i, key := range keys {
+		conds[i] = fmt.Sprintf("%s = %s", x.QuoteIdentifier(key), x.value(values[i]))
+	}
+
+	return fmt.Sprintf("DELETE FROM %s WHERE %s;\n", x.QuoteIdentifier(table), strings.Join(conds, ` AND `))
+}
+
+func (x Postgres) AddForeignKeyNotValid(table, column, referenceTable, referenceColumn string) string {
+	return strings.TrimSuffix(x.AddForeignKey(table, column, referenceTable, referenceColumn), ";\n") + " NOT VALID;\n"
+}
+
+func (x Postgres) ValidateForeignKey(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.constraint(`fk`, table, column))
+}
+
+func (x Postgres) AddCheckNotValid(table, column, check string) string {
+	return strings.TrimSuffix(x.AddCheck(table, column, check), ";\n") + " NOT VALID;\n"
+}
+
+func (x Postgres) ValidateCheck(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.constraint(`ch`, table, column))
+}
+
+// AddNotNullCheck adds an unvalidated check which, once validated, lets
+// SET NOT NULL skip the scan of the table
+func (x Postgres) AddNotNullCheck(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK(%s IS NOT NULL) NOT VALID;\n", x.QuoteIdentifier(table), x.constraint(`nn`, table, column), x.QuoteIdentifier(column))
+}
+
+func (x Postgres) ValidateNotNullCheck(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.constraint(`nn`, table, column))
+}
+
+func (x Postgres) DropNotNullCheck(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;\n", x.QuoteIdentifier(table), x.constraint(`nn`, table, column))
+}
+
+func (x Postgres) AddPrimaryKeyIndex(table string, columns []string) string {
+	return fmt.Sprintf("CREATE UNIQUE INDEX CONCURRENTLY %s ON %s (%s);\n", x.constraint(`pk`, table), x.QuoteIdentifier(table), x.list(columns))
+}
+
+func (x Postgres) DropPrimaryKeyIndex(table string) string {
+	return fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s;\n", x.index(`pk_`+local(table), table))
+}
+
+func (x Postgres) AddPrimaryKeyUsingIndex(table string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY USING INDEX %s;\n", x.QuoteIdentifier(table), x.constraint(`pk`, table), x.constraint(`pk`, table))
+}
+
+func (x Postgres) AddUniqueIndex(id, table string, columns []string) string {
+	return fmt.Sprintf("CREATE UNIQUE INDEX CONCURRENTLY %s ON %s (%s);\n", x.QuoteIdentifier(`uq_`+id), x.QuoteIdentifier(table), x.list(columns))
+}
+
+func (x Postgres) DropUniqueIndex(id, table string) string {
+	return fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s;\n", x.index(`uq_`+id, table))
+}
+
+func (x Postgres) AddUniqueUsingIndex(id, table string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE USING INDEX %s;\n", x.QuoteIdentifier(table), x.QuoteIdentifier(`uq_`+id), x.QuoteIdentifier(`uq_`+id))
 }
 
-func (x Postgres) SetAutoIncrement(table, column string) (q string) {
-	q += fmt.Sprintf("CREATE SEQUENCE seq_%s_%s;\n", table, column)
-	q += fmt.Sprintf("SELECT setval('seq_%s_%s', (SELECT max(%s) FROM %s));\n", table, column, column, table)
-	q += fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT nextval('seq_%s_%s'::regclass);\n", table, column, table, column)
+// SetTimeouts sets the lock and statement timeout of the session or,
+// when local, of the current transaction. A zero duration disables the timeout.
+func (x Postgres) SetTimeouts(lock, statement time.Duration, local bool) string {
+	set := `SET`
+	if local {
+		set = `SET LOCAL`
+	}
 
-	return
+	return fmt.Sprintf("%s lock_timeout = %d;\n%s statement_timeout = %d;\n", set, lock.Milliseconds(), set, statement.Milliseconds())
 }
 
-func (x Postgres) UnsetAutoIncrement(table, column string) (q string) {
-	q = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;\n", table, column)
-	q += fmt.Sprintf("DROP SEQUENCE seq_%s_%s CASCADE;", table, column)
+// IsLockTimeout checks if the error is caused by exceeding the lock timeout
+func (x Postgres) IsLockTimeout(err error) bool {
+	var pqerr *pq.Error
+	if errors.As(err, &pqerr) {
+		return pqerr.Code == `55P03`
+	}
 
-	return
+	return err != nil && strings.Contains(err.Error(), `lock timeout`)
 }
 
 func (x Postgres) AddVersionTable() string {
 	return "CREATE TABLE IF NOT EXISTS versions (id INT NOT NULL, config TEXT NOT NULL);\n"
 }
 
+func (x Postgres) HasVersionTable() string {
+	return "SELECT to_regclass('versions') IS NOT NULL;\n"
+}
+
 func (x Postgres) CheckVersion() string {
 	return "SELECT id, config FROM versions ORDER BY id DESC;\n"
 }
@@ -149,3 +362,78 @@ func (x Postgres) CheckVersion() string {
 func (x Postgres) InsertVersion() string {
 	return "INSERT INTO versions (id, config) VALUES($1, $2);\n"
 }
+
+// list returns the quoted identifiers as a comma separated list
+func (x Postgres) list(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = x.QuoteIdentifier(name)
+	}
+
+	return strings.Join(quoted, `, `)
+}
+
+// value returns the value as literal or NULL when no value is given
+func (x Postgres) value(value *string) string {
+	if value == nil {
+		return `NULL`
+	}
+
+	return x.QuoteLiteral(*value)
+}
+
+// values returns the values as a comma separated list of literals
+func (x Postgres) values(values []*string) string {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = x.value(value)
+	}
+
+	return strings.Join(literals, `, `)
+}
+
+// index returns the quoted name of an index in the schema of the given table,
+// unlike constraints indexes are dropped by their schema qualified name
+func (x Postgres) index(name, table string) string {
+	if i := strings.Index(table, `.`); i >= 0 {
+		name = table[:i+1] + name
+	}
+
+	return x.QuoteIdentifier(name)
+}
+
+// constraint returns the quoted name of a constraint of the given table,
+// constraints are scoped to the schema of their table so their names are never qualified
+func (x Postgres) constraint(prefix, table string, columns ...string) string {
+	return x.QuoteIdentifier(strings.Join(append([]string{prefix, local(table)}, columns...), `_`))
+}
+
+// local returns the table name without its schema
+func local(table string) string {
+	if i := strings.Index(table, `.`); i >= 0 {
+		return table[i+1:]
+	}
+
+	return table
+}
+
+// sequence returns the name of the sequence used for auto incrementing
+// the given column, the sequence is created in the schema of the table
+func sequence(table, column string) string {
+	name := fmt.Sprintf("seq_%s_%s", local(table), column)
+	if i := strings.Index(table, `.`); i >= 0 {
+		return table[:i+1] + name
+	}
+
+	return name
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/myceliums/assert"
+)
+
+func TestPostgresQuoting(t *testing.T) {
+	as := assert.New(t)
+	x := new(Postgres)
+
+	as.Eq(`"user"`, x.QuoteIdentifier(`user`))
+	as.Eq(`"billing"."order"`, x.QuoteIdentifier(`billing.order`))
+	as.Eq(`"a""b"`, x.QuoteIdentifier(`a"b`))
+	as.Eq(`'it''s'`, x.QuoteLiteral(`it's`))
+
+	as.Eq("ALTER TABLE \"order\" ADD COLUMN \"user\" VARCHAR(50);\n", x.AddColumn(`order`, `user`, `varchar`, 50))
+	as.Eq("CREATE TYPE \"mood\" AS ENUM ('ok', 'it''s fine''); DROP TABLE x; --');\n", x.AddEnum(`mood`, []string{`ok`, `it's fine'); DROP TABLE x; --`}))
+	as.Eq("ALTER TYPE \"mood\" ADD VALUE IF NOT EXISTS 'don''t';\n", x.AppendEnum(`mood`, `don't`))
+	as.Eq("ALTER TABLE \"order\" DROP CONSTRAINT \"uq_user\";\n", x.DropUnique(`user`, `order`))
+}
+
+func TestPostgresCast(t *testing.T) {
+	as := assert.New(t)
+	x := new(Postgres)
+
+	for _, c := range []struct {
+		from, to, using string
+		ok              bool
+	}{
+		{`int`, `bigint`, ``, true},
+		{`int`, `varchar`, ``, true},
+		{`mood`, `text`, ``, true},
+		{`boolean`, `int`, `"col"::INT`, true},
+		{`varchar`, `mood`, `"col"::text::"mood"`, true},
+		{`mood`, `billing.mood`, `"col"::text::"billing"."mood"`, true},
+		{`varchar`, `int`, ``, false},
+		{`timestamp`, `bigint`, ``, false},
+		{`mood`, `int`, ``, false},
+	} {
+		using, ok := x.Cast(`col`, c.from, c.to)
+		as.Eq(c.using, using, c.from, c.to)
+		as.Eq(c.ok, ok, c.from, c.to)
+	}
+
+	as.Eq("ALTER TABLE \"t\" ALTER COLUMN \"c\" TYPE INT USING trim(c)::int;\n", x.UpdateColumn(`t`, `c`, `int`, 0, `trim(c)::int`))
+}
@@ -0,0 +1,298 @@
+// Package docs renders the documentation of a model as Markdown or as a
+// standalone HTML page with every table, column, key, enum, view and relationship
+package docs
+
+import (
+	_ "embed"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/myceliums/gdb/model"
+)
+
+//go:embed markdown.tmpl
+var markdownTmpl string
+
+//go:embed html.tmpl
+var htmlTmpl string
+
+// Markdown writes the documentation of the model as Markdown
+func Markdown(wr io.Writer, title string, mdl model.Model) error {
+	t, err := template.New(`markdown`).Funcs(template.FuncMap{`cell`: cell}).Parse(markdownTmpl)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(wr, newDocument(title, mdl))
+}
+
+// HTML writes the documentation of the model as a standalone HTML page
+func HTML(wr io.Writer, title string, mdl model.Model) error {
+	t, err := htmltemplate.New(`html`).Parse(htmlTmpl)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(wr, newDocument(title, mdl))
+}
+
+// document is the template data of the documentation
+type document struct {
+	Title   string
+	Schemas []object
+	Tables  []table
+	Enums   []enum
+	Views   []view
+}
+
+// object is a named object with its anchor in the documentation
+type object struct {
+	Name        string
+	Anchor      string
+	Description string
+}
+
+type table struct {
+	object
+	Columns      []column
+	PrimaryKey   []string
+	Uniques      []unique
+	References   []reference
+	ReferencedBy []reference
+}
+
+type column struct {
+	Name        string
+	Type        string
+	TypeAnchor  string
+	NotNull     bool
+	Default     string
+	Check       string
+	Keys        []string
+	Description string
+}
+
+type unique struct {
+	Name    string
+	Columns []string
+}
+
+// reference is a foreign key from the column of a table to the column of another table
+type reference struct {
+	Table           string
+	Anchor          string
+	Column          string
+	ReferenceTable  string
+	ReferenceAnchor string
+	ReferenceColumn string
+}
+
+type enum struct {
+	object
+	Values []string
+	UsedBy []reference
+}
+
+type view struct {
+	object
+	Query        string
+	Materialized bool
+	Columns      []column
+}
+
+func newDocument(title string, mdl model.Model) document {
+	x := document{Title: title}
+
+	for _, name := range mdl.Schemas {
+		x.Schemas = append(x.Schemas, newObject(mdl, name))
+	}
+
+	var tables, uniques, enums, views []string
+	for name := range mdl.Tables {
+		tables = append(tables, name)
+	}
+	for id := range mdl.Uniques {
+		uniques = append(uniques, id)
+	}
+	for name := range mdl.Enums {
+		enums = append(enums, name)
+	}
+	for name := range mdl.Views {
+		views = append(views, name)
+	}
+	sort.Strings(tables)
+	sort.Strings(uniques)
+	sort.Strings(enums)
+	sort.Strings(views)
+
+	referencedBy := map[string][]reference{}
+	usedBy := map[string][]reference{}
+
+	for _, name := range tables {
+		t := table{object: newObject(mdl, name)}
+
+		for _, col := range sortedColumns(columnList(mdl.Tables[name])) {
+			t.Columns = append(t.Columns, newColumn(mdl, col))
+
+			if col.Primary != `` {
+				t.PrimaryKey = append(t.PrimaryKey, col.Name)
+			}
+
+			if col.Ref != nil {
+				ref := newReference(col)
+				t.References = append(t.References, ref)
+				referencedBy[col.Ref.Table] = append(referencedBy[col.Ref.Table], ref)
+			}
+
+			if enm, ok := col.Datatype.(*model.Enum); ok {
+				usedBy[enm.Name] = append(usedBy[enm.Name], reference{Table: name, Anchor: anchor(`table`, name), Column: col.Name})
+			}
+		}
+
+		for _, id := range uniques {
+			cols := mdl.Uniques[id]
+			if len(cols) == 0 || cols[0].Table != name {
+				continue
+			}
+
+			u := unique{Name: id}
+			for _, col := range sortedColumns(cols) {
+				u.Columns = append(u.Columns, col.Name)
+			}
+			t.Uniques = append(t.Uniques, u)
+		}
+
+		x.Tables = append(x.Tables, t)
+	}
+
+	for i := range x.Tables {
+		x.Tables[i].ReferencedBy = referencedBy[x.Tables[i].Name]
+	}
+
+	for _, name := range enums {
+		x.Enums = append(x.Enums, enum{object: newObject(mdl, name), Values: mdl.Enums[name].Values, UsedBy: usedBy[name]})
+	}
+
+	for _, name := range views {
+		v := mdl.Views[name]
+		doc := view{object: newObject(mdl, name), Query: v.Query, Materialized: v.Materialized}
+		for _, col := range sortedColumns(columnList(v.Columns)) {
+			doc.Columns = append(doc.Columns, newColumn(mdl, col))
+		}
+		x.Views = append(x.Views, doc)
+	}
+
+	return x
+}
+
+func newObject(mdl model.Model, name string) object {
+	kind := `table`
+	switch {
+	case mdl.Enums[name] != nil:
+		kind = `enum`
+	case mdl.Views[name] != nil:
+		kind = `view`
+	case mdl.Tables[name] == nil:
+		kind = `schema`
+	}
+
+	return object{Name: name, Anchor: anchor(kind, name), Description: mdl.Description(name)}
+}
+
+func newColumn(mdl model.Model, col *model.Column) column {
+	x := column{
+		Name:        col.Name,
+		Type:        typeName(col),
+		NotNull:     col.NotNull || col.Primary != ``,
+		Default:     col.Default,
+		Check:       col.Check,
+		Description: mdl.Description(col.Table + `.` + col.Name),
+	}
+
+	if enm, ok := col.Datatype.(*model.Enum); ok {
+		x.TypeAnchor = anchor(`enum`, enm.Name)
+	}
+
+	if x.Default == `` && col.AutoIncement {
+		x.Default = `auto increment`
+	}
+
+	if col.Primary != `` {
+		x.Keys = append(x.Keys, `primary key`)
+	}
+
+	if col.Unique != `` {
+		x.Keys = append(x.Keys, `unique `+col.Unique)
+	}
+
+	if col.Ref != nil {
+		x.Keys = append(x.Keys, `references `+col.Ref.Table+`.`+col.Ref.Name)
+	}
+
+	return x
+}
+
+func newReference(col *model.Column) reference {
+	return reference{
+		Table:           col.Table,
+		Anchor:          anchor(`table`, col.Table),
+		Column:          col.Name,
+		ReferenceTable:  col.Ref.Table,
+		ReferenceAnchor: anchor(`table`, col.Ref.Table),
+		ReferenceColumn: col.Ref.Name,
+	}
+}
+
+// typeName returns the type of the column with its size, a foreign key has the
+// type of the column it references
+func typeName(col *model.Column) string {
+	size := col.Size
+	for ref := col.Ref; ref != nil; ref = ref.Ref {
+		size = ref.Size
+	}
+
+	if size > 0 {
+		return col.Type() + `(` + strconv.Itoa(size) + `)`
+	}
+
+	return col.Type()
+}
+
+// anchor returns the id of the object in the documentation, the kind is part
+// of the id as tables, enums and views can have the same name in the markdown
+func anchor(kind, name string) string {
+	return kind + `-` + strings.Replace(name, `.`, `-`, -1)
+}
+
+// cell escapes the text for a cell of a markdown table
+func cell(s string) string {
+	s = strings.Replace(s, `|`, `\|`, -1)
+	return strings.Join(strings.Fields(s), ` `)
+}
+
+// sortedColumns returns the columns with the primary key first, sorted by name
+func sortedColumns(cols []*model.Column) []*model.Column {
+	list := append([]*model.Column{}, cols...)
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Primary != ``) != (list[j].Primary != ``) {
+			return list[i].Primary != ``
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// columnList returns the columns of a table or view
+func columnList(cols map[string]*model.Column) []*model.Column {
+	var list []*model.Column
+	for _, col := range cols {
+		list = append(list, col)
+	}
+
+	return list
+}
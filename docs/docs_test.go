@@ -0,0 +1,74 @@
+package docs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/model"
+)
+
+var config = []byte(`# the users that can log in
+accounts:
+  id: serial primary
+  email: varchar(100) not null unique(email) # the login | address
+  status: account_status default('active')
+  description:
+    status: <blocked> accounts can't log in
+
+posts:
+  id: int primary
+  author: accounts.id not null
+
+account_status:
+- active
+- blocked
+
+recent_posts:
+  view: SELECT id FROM posts
+  id: int
+`)
+
+func TestMarkdown(t *testing.T) {
+	as := assert.New(t)
+
+	mdl, err := model.New(config)
+	as.NoError(err)
+
+	buf := &bytes.Buffer{}
+	as.NoError(Markdown(buf, `Accounts`, *mdl))
+	out := buf.String()
+
+	for _, s := range []string{
+		"# Accounts\n",
+		"### <a id=\"table-accounts\"></a>accounts\n\nthe users that can log in\n",
+		"| id | int | yes | auto increment |  | primary key |  |\n",
+		"| email | varchar(100) | yes |  |  | unique email | the login \\| address |\n",
+		"| status | [account_status](#enum-account_status) | no | 'active' |  |  | <blocked> accounts can't log in |\n",
+		"| author | int | yes |  |  | references accounts.id |  |\n",
+		"- author → [accounts](#table-accounts).id\n",
+		"- [posts](#table-posts).author → id\n",
+		"**Values:** `active`, `blocked`\n",
+		"**Used by:** [accounts](#table-accounts).status\n",
+		"```sql\nSELECT id FROM posts\n```\n",
+	} {
+		as.True(strings.Contains(out, s), s)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	as := assert.New(t)
+
+	mdl, err := model.New(config)
+	as.NoError(err)
+
+	buf := &bytes.Buffer{}
+	as.NoError(HTML(buf, `Accounts`, *mdl))
+	out := buf.String()
+
+	as.True(strings.HasPrefix(out, "<!DOCTYPE html>"))
+	as.True(strings.Contains(out, `<section id="table-posts">`))
+	as.True(strings.Contains(out, `<a href="#enum-account_status">account_status</a>`))
+	as.True(strings.Contains(out, `&lt;blocked&gt; accounts can&#39;t log in`))
+}
@@ -0,0 +1,198 @@
+package docs
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/myceliums/gdb/model"
+)
+
+// Diagram is the language of an entity relationship diagram
+type Diagram string
+
+const (
+	// Mermaid writes an erDiagram of mermaid.js
+	Mermaid Diagram = `mermaid`
+
+	// DOT writes a digraph of Graphviz with a html table per entity
+	DOT Diagram = `dot`
+
+	// PlantUML writes the entities and relations in the Information Engineering notation of PlantUML
+	PlantUML Diagram = `plantuml`
+)
+
+// relation is a foreign key with the cardinality of both sides, the
+// referenced row is optional when the foreign key is nullable and a
+// row can only be referenced once when the foreign key is unique
+type relation struct {
+	col      *model.Column
+	optional bool
+	unique   bool
+}
+
+// ERD writes the entity relationship diagram of the tables of the model
+func ERD(wr io.Writer, mdl model.Model, diagram Diagram) error {
+	var tables []string
+	for name := range mdl.Tables {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+
+	var relations []relation
+	for _, name := range tables {
+		for _, col := range sortedColumns(columnList(mdl.Tables[name])) {
+			if col.Ref != nil {
+				relations = append(relations, relation{col: col, optional: !col.NotNull && col.Primary == ``, unique: isUnique(mdl, col)})
+			}
+		}
+	}
+
+	var builder strings.Builder
+	switch diagram {
+	case Mermaid:
+		writeMermaid(&builder, mdl, tables, relations)
+	case DOT:
+		writeDOT(&builder, mdl, tables, relations)
+	case PlantUML:
+		writePlantUML(&builder, mdl, tables, relations)
+	default:
+		return fmt.Errorf("unknown diagram %s, want mermaid, dot or plantuml", diagram)
+	}
+
+	_, err := io.WriteString(wr, builder.String())
+	return err
+}
+
+func writeMermaid(builder *strings.Builder, mdl model.Model, tables []string, relations []relation) {
+	builder.WriteString("erDiagram\n") // nolint: errcheck
+
+	for _, name := range tables {
+		fmt.Fprintf(builder, "    %s {\n", entity(name)) // nolint: errcheck
+		for _, col := range sortedColumns(columnList(mdl.Tables[name])) {
+			line := fmt.Sprintf("        %s %s", strings.Replace(typeName(col), `.`, `_`, -1), col.Name)
+			if keys := keyMarkers(col); len(keys) > 0 {
+				line += ` ` + strings.Join(keys, `, `)
+			}
+			builder.WriteString(line + "\n") // nolint: errcheck
+		}
+		builder.WriteString("    }\n") // nolint: errcheck
+	}
+
+	for _, r := range relations {
+		parent, child := `||`, `o{`
+		if r.optional {
+			parent = `|o`
+		}
+		if r.unique {
+			child = `o|`
+		}
+
+		fmt.Fprintf(builder, "    %s %s--%s %s : %s\n", entity(r.col.Ref.Table), parent, child, entity(r.col.Table), r.col.Name) // nolint: errcheck
+	}
+}
+
+func writeDOT(builder *strings.Builder, mdl model.Model, tables []string, relations []relation) {
+	builder.WriteString("digraph erd {\n  rankdir=LR;\n  node [shape=plaintext, fontname=\"Helvetica\"];\n  edge [dir=both];\n") // nolint: errcheck
+
+	for _, name := range tables {
+		fmt.Fprintf(builder, "  %q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\" cellpadding=\"4\">\n", name) // nolint: errcheck
+		fmt.Fprintf(builder, "    <tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", name)                                  // nolint: errcheck
+		for _, col := range sortedColumns(columnList(mdl.Tables[name])) {
+			label := col.Name + `: ` + typeName(col)
+			if keys := keyMarkers(col); len(keys) > 0 {
+				label += ` ` + strings.Join(keys, `, `)
+			}
+			fmt.Fprintf(builder, "    <tr><td port=%q align=\"left\">%s</td></tr>\n", col.Name, template.HTMLEscapeString(label)) // nolint: errcheck
+		}
+		builder.WriteString("  </table>>];\n") // nolint: errcheck
+	}
+
+	// the first shape of an arrow is drawn closest to the table
+	for _, r := range relations {
+		parent, child := `teetee`, `crowodot`
+		if r.optional {
+			parent = `teeodot`
+		}
+		if r.unique {
+			child = `teeodot`
+		}
+
+		fmt.Fprintf(builder, "  %q:%q -> %q:%q [arrowhead=%s, arrowtail=%s];\n", r.col.Table, r.col.Name, r.col.Ref.Table, r.col.Ref.Name, parent, child) // nolint: errcheck
+	}
+
+	builder.WriteString("}\n") // nolint: errcheck
+}
+
+func writePlantUML(builder *strings.Builder, mdl model.Model, tables []string, relations []relation) {
+	builder.WriteString("@startuml\nhide circle\nskinparam linetype ortho\n") // nolint: errcheck
+
+	for _, name := range tables {
+		fmt.Fprintf(builder, "\nentity %q as %s {\n", name, entity(name)) // nolint: errcheck
+
+		cols := sortedColumns(columnList(mdl.Tables[name]))
+		for i, col := range cols {
+			if i > 0 && cols[i-1].Primary != `` && col.Primary == `` {
+				builder.WriteString("  --\n") // nolint: errcheck
+			}
+
+			line := `  `
+			if col.NotNull || col.Primary != `` {
+				line += `* `
+			}
+			line += col.Name + ` : ` + typeName(col)
+			for _, key := range keyMarkers(col) {
+				line += ` <<` + key + `>>`
+			}
+			builder.WriteString(line + "\n") // nolint: errcheck
+		}
+		builder.WriteString("}\n") // nolint: errcheck
+	}
+
+	if len(relations) > 0 {
+		builder.WriteString("\n") // nolint: errcheck
+	}
+
+	for _, r := range relations {
+		parent, child := `||`, `o{`
+		if r.optional {
+			parent = `|o`
+		}
+		if r.unique {
+			child = `o|`
+		}
+
+		fmt.Fprintf(builder, "%s %s--%s %s : %s\n", entity(r.col.Ref.Table), parent, child, entity(r.col.Table), r.col.Name) // nolint: errcheck
+	}
+
+	builder.WriteString("@enduml\n") // nolint: errcheck
+}
+
+// keyMarkers returns PK, FK and UK for the keys the column is part of
+func keyMarkers(col *model.Column) []string {
+	var keys []string
+	if col.Primary != `` {
+		keys = append(keys, `PK`)
+	}
+	if col.Ref != nil {
+		keys = append(keys, `FK`)
+	}
+	if col.Unique != `` {
+		keys = append(keys, `UK`)
+	}
+
+	return keys
+}
+
+// isUnique returns true when the column is the only column of the primary key or of a unique constraint
+func isUnique(mdl model.Model, col *model.Column) bool {
+	return (col.Primary != `` && len(mdl.Primaries[col.Table]) == 1) ||
+		(col.Unique != `` && len(mdl.Uniques[col.Unique]) == 1)
+}
+
+// entity returns the name of the table as identifier of a diagram, the schema is separated by an underscore
+func entity(table string) string {
+	return strings.Replace(table, `.`, `_`, -1)
+}
@@ -0,0 +1,53 @@
+package docs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/model"
+)
+
+func TestERD(t *testing.T) {
+	as := assert.New(t)
+
+	mdl, err := model.New([]byte(`
+accounts:
+  id: int primary
+  email: varchar(100) not null unique(email)
+
+profiles:
+  id: int primary
+  account: accounts.id not null unique(account)
+
+posts:
+  id: int primary
+  author: accounts.id
+`))
+	as.NoError(err)
+
+	diagram := func(d Diagram) string {
+		buf := &bytes.Buffer{}
+		as.NoError(ERD(buf, *mdl, d))
+		return buf.String()
+	}
+
+	mermaid := diagram(Mermaid)
+	as.True(strings.HasPrefix(mermaid, "erDiagram\n    accounts {\n        int id PK\n        varchar(100) email UK\n    }\n"))
+	as.True(strings.Contains(mermaid, "    int account FK, UK\n"))
+	as.True(strings.Contains(mermaid, "    accounts |o--o{ posts : author\n"))
+	as.True(strings.Contains(mermaid, "    accounts ||--o| profiles : account\n"))
+
+	dot := diagram(DOT)
+	as.True(strings.Contains(dot, `<tr><td port="email" align="left">email: varchar(100) UK</td></tr>`))
+	as.True(strings.Contains(dot, `"posts":"author" -> "accounts":"id" [arrowhead=teeodot, arrowtail=crowodot];`))
+	as.True(strings.Contains(dot, `"profiles":"account" -> "accounts":"id" [arrowhead=teetee, arrowtail=teeodot];`))
+
+	plantuml := diagram(PlantUML)
+	as.True(strings.Contains(plantuml, "entity \"posts\" as posts {\n  * id : int <<PK>>\n  --\n  author : int <<FK>>\n}\n"))
+	as.True(strings.Contains(plantuml, "accounts |o--o{ posts : author\n"))
+	as.True(strings.HasSuffix(plantuml, "@enduml\n"))
+
+	as.Error(ERD(&bytes.Buffer{}, *mdl, `svg`))
+}
@@ -0,0 +1,274 @@
+// Package export writes the migrations between versions of a configuration as
+// numbered sql files which can be applied by golang-migrate or goose
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/myceliums/gdb/dialect"
+	"github.com/myceliums/gdb/model"
+)
+
+// Format is the naming and layout of the migration files
+type Format string
+
+const (
+	// Migrate writes a <version>_<name>.up.sql and <version>_<name>.down.sql file
+	// per version as read by golang-migrate
+	Migrate Format = `migrate`
+
+	// Goose writes a single <version>_<name>.sql file per version with the
+	// up and down migration annotated as read by goose
+	Goose Format = `goose`
+)
+
+// slugReg matches the characters which are replaced in the names of the files
+var slugReg = regexp.MustCompile(`[^a-z0-9]+`)
+
+// maxSlugLength is the maximum length of the name part of a file
+const maxSlugLength = 50
+
+// Snapshot is a version of the configuration
+type Snapshot struct {
+	Name  string
+	Model model.Model
+}
+
+// FromDir reads the snapshots of a directory in the order of their names, every
+// .yml or .yaml file and every subdirectory of configuration files is a snapshot
+func FromDir(dir string) ([]Snapshot, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if ext := filepath.Ext(f.Name()); f.IsDir() || ext == `.yml` || ext == `.yaml` {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var snapshots []Snapshot
+	for _, name := range names {
+		mdl, err := model.NewFromFiles(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: %v", name, err)
+		}
+
+		snapshots = append(snapshots, Snapshot{Name: strings.TrimSuffix(name, filepath.Ext(name)), Model: *mdl})
+	}
+
+	return snapshots, nil
+}
+
+// FromGit reads the snapshots of the configuration file from the git history, every
+// commit changing the file is a snapshot. The revisions limit the commits, e.g. v1.0..HEAD,
+// when empty the whole history of the file is read.
+func FromGit(path, revisions string) ([]Snapshot, error) {
+	dir, file := filepath.Split(path)
+	if dir == `` {
+		dir = `.`
+	}
+
+	args := []string{`log`, `--reverse`, `--format=%h %s`}
+	if revisions != `` {
+		args = append(args, revisions)
+	}
+	args = append(args, `--`, file)
+
+	// a commit which only changes a file the configuration includes is a snapshot as well
+	included, _ := model.Files(path)
+	for _, inc := range included {
+		if rel, err := filepath.Rel(dir, inc); err == nil && rel != file {
+			args = append(args, rel)
+		}
+	}
+
+	out, err := git(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == `` {
+			continue
+		}
+
+		snapshot, err := FromRevision(path, strings.Fields(line)[0])
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot.Name = line
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// FromRevision reads the configuration file as it is at the given git revision, with the
+// files it includes at that revision. The configuration was valid when it was committed, so
+// it's read like a configuration stored by a migration, see model.NewFromHistory.
+func FromRevision(path, revision string) (Snapshot, error) {
+	dir, file := filepath.Split(path)
+	if dir == `` {
+		dir = `.`
+	}
+
+	prefix, err := git(dir, `rev-parse`, `--show-prefix`)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	tmp, err := ioutil.TempDir(``, `gdb-revision`)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+
+	archive, err := git(dir, `archive`, `--format=tar`, revision)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := extract(archive, tmp); err != nil {
+		return Snapshot{}, fmt.Errorf("revision %s: %v", revision, err)
+	}
+
+	mdl, err := model.NewFromHistory(filepath.Join(tmp, filepath.FromSlash(strings.TrimSpace(string(prefix))), file))
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("revision %s: %s", revision, strings.Replace(err.Error(), tmp+string(filepath.Separator), ``, -1))
+	}
+
+	return Snapshot{Name: revision, Model: *mdl}, nil
+}
+
+// extract writes the configuration files of the tar archive of a revision to the directory
+func extract(archive []byte, dir string) error {
+	r := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if ext := path.Ext(header.Name); header.Typeflag != tar.TypeReg || (ext != `.yml` && ext != `.yaml`) {
+			continue
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(path.Clean(`/`+header.Name)))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		in, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(target, in, 0644); err != nil {
+			return err
+		}
+	}
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command(`git`, append([]string{`-C`, dir}, args...)...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// Write writes the migrations between the consecutive snapshots to the directory and
+// returns the written files. The first snapshot is migrated from an empty database,
+// snapshots without changes are skipped.
+func Write(dia dialect.Dialect, snapshots []Snapshot, dir string, format Format) ([]string, error) {
+	if format != Migrate && format != Goose {
+		return nil, fmt.Errorf("unknown format %s, should be %s or %s", format, Migrate, Goose)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	var version int
+	prev := model.Model{}
+	for _, snapshot := range snapshots {
+		up := model.Diff(prev, snapshot.Model).SQL(dia)
+		down := model.Diff(snapshot.Model, prev).SQL(dia)
+		prev = snapshot.Model
+
+		if up == `` {
+			continue
+		}
+
+		version++
+		base := fmt.Sprintf("%06d_%s", version, slug(snapshot.Name))
+
+		written, err := writeVersion(dir, base, snapshot.Name, up, down, format)
+		if err != nil {
+			return files, err
+		}
+
+		files = append(files, written...)
+	}
+
+	return files, nil
+}
+
+func writeVersion(dir, base, name, up, down string, format Format) ([]string, error) {
+	header := fmt.Sprintf("-- %s\n", name)
+
+	if format == Goose {
+		content := header + "-- +goose Up\n-- +goose StatementBegin\n" + up + "-- +goose StatementEnd\n\n" +
+			"-- +goose Down\n-- +goose StatementBegin\n" + down + "-- +goose StatementEnd\n"
+
+		path := filepath.Join(dir, base+`.sql`)
+		return []string{path}, ioutil.WriteFile(path, []byte(content), 0644)
+	}
+
+	uppath, downpath := filepath.Join(dir, base+`.up.sql`), filepath.Join(dir, base+`.down.sql`)
+	if err := ioutil.WriteFile(uppath, []byte(header+up), 0644); err != nil {
+		return nil, err
+	}
+
+	return []string{uppath, downpath}, ioutil.WriteFile(downpath, []byte(header+down), 0644)
+}
+
+// slug returns the name as lowercase words joined by underscores
+func slug(name string) string {
+	s := strings.Trim(slugReg.ReplaceAllString(strings.ToLower(name), `_`), `_`)
+	if len(s) > maxSlugLength {
+		s = strings.TrimRight(s[:maxSlugLength], `_`)
+	}
+
+	if s == `` {
+		return `migration`
+	}
+
+	return s
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/dialect"
+)
+
+func writeSnapshots(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir(``, `gdb-export`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) }) // nolint: errcheck
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func read(t *testing.T, path string) string {
+	in, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(in)
+}
+
+func TestWriteMigrate(t *testing.T) {
+	as := assert.New(t)
+	dir := writeSnapshots(t, map[string]string{
+		`001-initial.yml`: "accounts:\n  id: int primary\n",
+		`002-same.yml`:    "accounts:\n  id: int primary\n",
+		`003-email.yml`:   "accounts:\n  id: int primary\n  email: varchar\n",
+	})
+
+	snapshots, err := FromDir(dir)
+	as.NoError(err)
+	as.Eq(3, len(snapshots))
+
+	out := filepath.Join(dir, `migrations`)
+	files, err := Write(dialect.GetByDriver(`postgres`), snapshots, out, Migrate)
+	as.NoError(err)
+	as.Cmp([]string{
+		filepath.Join(out, `000001_001_initial.up.sql`),
+		filepath.Join(out, `000001_001_initial.down.sql`),
+		filepath.Join(out, `000002_003_email.up.sql`),
+		filepath.Join(out, `000002_003_email.down.sql`),
+	}, files)
+
+	as.True(strings.Contains(read(t, files[0]), `CREATE TABLE "accounts"`))
+	as.True(strings.Contains(read(t, files[1]), `DROP TABLE "accounts" CASCADE;`))
+	as.False(strings.Contains(read(t, files[1]), `DROP CONSTRAINT`))
+	as.True(strings.Contains(read(t, files[2]), `ALTER TABLE "accounts" ADD COLUMN "email" VARCHAR;`))
+	as.True(strings.Contains(read(t, files[3]), `ALTER TABLE "accounts" DROP COLUMN "email";`))
+}
+
+func TestWriteGoose(t *testing.T) {
+	as := assert.New(t)
+	dir := writeSnapshots(t, map[string]string{
+		`v1.yml`: "status:\n- active\n",
+	})
+
+	snapshots, err := FromDir(dir)
+	as.NoError(err)
+
+	files, err := Write(dialect.GetByDriver(`postgres`), snapshots, dir, Goose)
+	as.NoError(err)
+	as.Cmp([]string{filepath.Join(dir, `000001_v1.sql`)}, files)
+
+	content := read(t, files[0])
+	up, down := strings.Index(content, `-- +goose Up`), strings.Index(content, `-- +goose Down`)
+	as.True(up >= 0 && down > up)
+	as.True(strings.Index(content, `CREATE TYPE "status"`) > up)
+	as.True(strings.Index(content, `DROP TYPE "status"`) > down)
+
+	_, err = Write(dialect.GetByDriver(`postgres`), snapshots, dir, Format(`flyway`))
+	as.Error(err)
+}
+
+func TestFromGit(t *testing.T) {
+	as := assert.New(t)
+	if _, err := exec.LookPath(`git`); err != nil {
+		t.Skip(`git is not installed`)
+	}
+
+	dir := writeSnapshots(t, nil)
+	run := func(args ...string) {
+		cmd := exec.Command(`git`, append([]string{`-C`, dir, `-c`, `user.name=test`, `-c`, `user.email=test@example.com`}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run(`init`, `-q`)
+	// the first revision predates the lowercase names
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, `db.yml`), []byte("Accounts:\n  ID: int primary\n"), 0644))
+	run(`add`, `db.yml`)
+	run(`commit`, `-q`, `-m`, `Add accounts`)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, `db.yml`), []byte("accounts:\n  id: int primary\n  name: varchar\n"), 0644))
+	run(`commit`, `-q`, `-am`, `Add account names`)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, `db.yml`), []byte("include: billing.yml\naccounts:\n  id: int primary\n  name: varchar\n"), 0644))
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, `billing.yml`), []byte("invoices:\n  id: int primary\n"), 0644))
+	run(`add`, `billing.yml`)
+	run(`commit`, `-q`, `-am`, `Add invoices`)
+	as.NoError(ioutil.WriteFile(filepath.Join(dir, `billing.yml`), []byte("invoices:\n  id: int primary\n  total: int\n"), 0644))
+	run(`commit`, `-q`, `-am`, `Add invoice totals`)
+
+	snapshots, err := FromGit(filepath.Join(dir, `db.yml`), ``)
+	as.NoError(err)
+	as.Eq(4, len(snapshots))
+	as.Eq(1, len(snapshots[0].Model.Tables[`accounts`]))
+	as.True(strings.HasSuffix(snapshots[1].Name, ` Add account names`))
+	as.Eq(2, len(snapshots[1].Model.Tables[`accounts`]))
+	as.Eq(1, len(snapshots[2].Model.Tables[`invoices`]))
+	as.True(strings.HasSuffix(snapshots[3].Name, ` Add invoice totals`))
+	as.Eq(2, len(snapshots[3].Model.Tables[`invoices`]))
+
+	snapshots, err = FromGit(filepath.Join(dir, `db.yml`), `HEAD~1..HEAD`)
+	as.NoError(err)
+	as.Eq(1, len(snapshots))
+
+	_, err = FromRevision(filepath.Join(dir, `missing.yml`), `HEAD`)
+	as.Error(err)
+	as.False(strings.Contains(err.Error(), `gdb-revision`), err)
+}
@@ -0,0 +1,113 @@
+package format
+
+import "strings"
+
+// normalize returns the column definition with the type first, followed by the
+// modifiers in the order primary, auto increment, unique, not null, default, check
+// and using, with the long spelling of the modifiers. Unknown parts keep their
+// order and are added at the end.
+func normalize(def string) string {
+	tokens := tokenize(def)
+	if len(tokens) == 0 {
+		return def
+	}
+
+	var primary, autoIncrement, notNull bool
+	var unique, dflt, check, using string
+	var rest []string
+
+	for i := 1; i < len(tokens); i++ {
+		token, next := tokens[i], ``
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		switch {
+		case token == `primary` && next == `key`:
+			primary = true
+			i++
+		case token == `primary` || token == `primarykey`:
+			primary = true
+		case token == `auto` && next == `increment`:
+			autoIncrement = true
+			i++
+		case token == `autoincrement`:
+			autoIncrement = true
+		case token == `not` && next == `null`:
+			notNull = true
+			i++
+		case token == `notnull`:
+			notNull = true
+		case unique == `` && (token == `unique` || strings.HasPrefix(token, `unique(`)):
+			unique = token
+		case dflt == `` && strings.HasPrefix(token, `default(`):
+			dflt = token
+		case check == `` && strings.HasPrefix(token, `check(`):
+			check = token
+		case using == `` && strings.HasPrefix(token, `using(`):
+			using = token
+		default:
+			rest = append(rest, token)
+		}
+	}
+
+	parts := []string{tokens[0]}
+	if primary {
+		parts = append(parts, `primary`)
+	}
+	if autoIncrement {
+		parts = append(parts, `auto increment`)
+	}
+	if unique != `` {
+		parts = append(parts, unique)
+	}
+	if notNull {
+		parts = append(parts, `not null`)
+	}
+	for _, part := range []string{dflt, check, using} {
+		if part != `` {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(append(parts, rest...), ` `)
+}
+
+// tokenize splits the definition on the spaces outside of parentheses and quotes
+func tokenize(def string) []string {
+	var tokens []string
+	var depth int
+	var quote rune
+	start := -1
+
+	for i, r := range def {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case (r == ' ' || r == '\t') && depth == 0:
+			if start >= 0 {
+				tokens = append(tokens, def[start:i])
+				start = -1
+			}
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, def[start:])
+	}
+
+	return tokens
+}
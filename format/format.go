@@ -0,0 +1,564 @@
+// Package format rewrites configurations in their canonical form: columns
+// with normalized modifiers in a fixed order, aligned definitions and the
+// objects sorted, while the comments are kept with the lines they describe
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/myceliums/gdb/model"
+)
+
+// Order is the order of the tables in the formatted configuration
+type Order string
+
+const (
+	// Dependency sorts the tables after the tables they reference, tables
+	// without a dependency between them are sorted by name
+	Dependency Order = `dependency`
+
+	// Name sorts the tables by name
+	Name Order = `name`
+)
+
+// Options are the options of the formatter
+type Options struct {
+	// Order is the order of the tables, the default is Dependency
+	Order Order
+}
+
+// Source formats the given configuration. The objects are written in the order
+// include, enums, tables, views and schemas, with the objects of a schema in the
+// same order. An error is returned when the configuration can't be parsed or
+// when the formatted configuration would result in a different model.
+func Source(in []byte, opts Options) ([]byte, error) {
+	switch opts.Order {
+	case ``:
+		opts.Order = Dependency
+	case Dependency, Name:
+	default:
+		return nil, fmt.Errorf("unknown order %s, want dependency or name", opts.Order)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(in, &doc); err != nil {
+		return nil, err
+	}
+
+	in = bytes.Replace(in, []byte("\r\n"), []byte("\n"), -1)
+	p := &parser{lines: strings.Split(string(in), "\n"), keys: model.KeyLines(in)}
+	header := p.header()
+
+	nodes, err := p.mapping(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.pos+1)
+	}
+
+	buf := &bytes.Buffer{}
+	for _, c := range header {
+		buf.WriteString(c + "\n") // nolint: errcheck
+	}
+	if len(header) > 0 && len(nodes) > 0 {
+		buf.WriteString("\n") // nolint: errcheck
+	}
+
+	writeObjects(buf, ``, sortObjects(nodes, ``, opts.Order), ``)
+
+	if len(p.footer) > 0 {
+		buf.WriteString("\n") // nolint: errcheck
+		for _, c := range p.footer {
+			buf.WriteString(c + "\n") // nolint: errcheck
+		}
+	}
+
+	out := buf.Bytes()
+	if err := verify(in, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// verify checks that the formatted configuration results in the same model,
+// configurations which aren't complete on their own, like included files, can't
+// be compared and are skipped
+func verify(in, out []byte) error {
+	prev, err := model.New(in)
+	if err != nil {
+		return nil
+	}
+
+	curr, err := model.New(out)
+	if err != nil {
+		return fmt.Errorf("the formatted configuration is invalid: %v", err)
+	}
+
+	if changes := model.Diff(*prev, *curr).Changes; len(changes) > 0 {
+		return fmt.Errorf("the formatted configuration changes the model: %s", changes[0])
+	}
+
+	return nil
+}
+
+// node is a key of the configuration with its value, which is either a scalar,
+// a mapping of child nodes or a body of raw lines like a sequence or a block scalar
+type node struct {
+	comments []string
+	key      string
+	value    string
+	comment  string
+	children []*node
+	body     []string
+	sequence bool
+}
+
+// isQuery returns true when the node is the key of a view, a block scalar or a query,
+// otherwise it's a column named view
+func (x *node) isQuery() bool {
+	return x != nil && (x.body != nil || model.IsQuery(strings.Trim(x.value, `"'`)))
+}
+
+// isMapping returns true when the node contains child keys
+func (x *node) isMapping() bool {
+	return len(x.children) > 0
+}
+
+// isSequence returns true when the node contains a block or flow sequence
+func (x *node) isSequence() bool {
+	return x.sequence || strings.HasPrefix(x.value, `[`)
+}
+
+// child returns the child node with the given key
+func (x *node) child(key string) *node {
+	for _, c := range x.children {
+		if c.key == key {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// kind returns the kind of object the node configures, like the model does
+func (x *node) kind() string {
+	switch {
+	case x.key == `include`:
+		return `include`
+	case x.isSequence() || (x.child(`enum`) != nil && x.child(`enum`).isSequence()):
+		return `enum`
+	case x.child(`view`).isQuery():
+		return `view`
+	case x.isMapping() && x.isSchema():
+		return `schema`
+	}
+
+	return `table`
+}
+
+// isSchema returns true when all children are tables, enums or views
+func (x *node) isSchema() bool {
+	for _, c := range x.children {
+		if !c.isMapping() && !c.isSequence() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortObjects sorts the objects of the configuration or a schema in the canonical order
+func sortObjects(nodes []*node, schema string, order Order) []*node {
+	groups := map[string][]*node{}
+	for _, n := range nodes {
+		groups[n.kind()] = append(groups[n.kind()], n)
+	}
+
+	for _, kind := range []string{`enum`, `view`, `schema`, `table`} {
+		sort.SliceStable(groups[kind], func(i, j int) bool { return groups[kind][i].key < groups[kind][j].key })
+	}
+
+	if order == Dependency {
+		groups[`table`] = sortByDependency(groups[`table`], schema)
+	}
+
+	for _, n := range groups[`schema`] {
+		n.children = sortObjects(n.children, n.key, order)
+	}
+
+	var sorted []*node
+	for _, kind := range []string{`include`, `enum`, `table`, `view`, `schema`} {
+		sorted = append(sorted, groups[kind]...)
+	}
+
+	return sorted
+}
+
+// sortByDependency sorts the tables, which are sorted by name, after the tables
+// they reference, tables in a reference cycle keep their order
+func sortByDependency(tables []*node, schema string) []*node {
+	names := map[string]bool{}
+	for _, t := range tables {
+		names[t.key] = true
+	}
+
+	deps := map[string]map[string]bool{}
+	for _, t := range tables {
+		deps[t.key] = map[string]bool{}
+		for _, c := range t.children {
+			if c.isMapping() || c.body != nil {
+				continue
+			}
+
+			ref := referencedTable(definition(c.value), schema)
+			if ref != t.key && names[ref] {
+				deps[t.key][ref] = true
+			}
+		}
+	}
+
+	var sorted []*node
+	done := map[string]bool{}
+	for len(sorted) < len(tables) {
+		progress := false
+		for _, t := range tables {
+			if done[t.key] {
+				continue
+			}
+
+			ready := true
+			for dep := range deps[t.key] {
+				ready = ready && done[dep]
+			}
+
+			if ready {
+				sorted = append(sorted, t)
+				done[t.key] = true
+				progress = true
+				break
+			}
+		}
+
+		if progress {
+			continue
+		}
+
+		// a cycle, the first remaining table is added without its references
+		for _, t := range tables {
+			if !done[t.key] {
+				sorted = append(sorted, t)
+				done[t.key] = true
+				break
+			}
+		}
+	}
+
+	return sorted
+}
+
+// referencedTable returns the table referenced by the type of the column
+// definition, references to other schemas are ignored
+func referencedTable(def, schema string) string {
+	tokens := tokenize(def)
+	if len(tokens) == 0 {
+		return ``
+	}
+
+	parts := strings.Split(tokens[0], `.`)
+	switch {
+	case len(parts) == 2:
+		return parts[0]
+	case len(parts) == 3 && parts[0] == schema:
+		return parts[1]
+	}
+
+	return ``
+}
+
+// writeObjects writes the objects separated by an empty line
+func writeObjects(buf *bytes.Buffer, indent string, nodes []*node, parentKind string) {
+	for i, n := range nodes {
+		if i > 0 {
+			buf.WriteString("\n") // nolint: errcheck
+		}
+
+		writeNode(buf, indent, n, 0, parentKind)
+		if n.kind() == `schema` && parentKind == `` {
+			writeObjects(buf, indent+`  `, n.children, `schema`)
+		}
+	}
+}
+
+// writeNode writes the node, the scalar value is aligned at the given width
+func writeNode(buf *bytes.Buffer, indent string, n *node, width int, parentKind string) {
+	for _, c := range n.comments {
+		buf.WriteString(indent + c + "\n") // nolint: errcheck
+	}
+
+	kind := n.kind()
+	value := n.value
+	column := parentKind == `table` || (parentKind == `view` && n.key != `view` && n.key != `materialized`)
+	if column && value != `` && n.body == nil && !n.isMapping() {
+		value = quote(normalize(definition(value)))
+	}
+
+	line := indent + n.key + `:`
+	if value != `` {
+		pad := 1
+		if width > len(n.key) {
+			pad += width - len(n.key)
+		}
+		line += strings.Repeat(` `, pad) + value
+	}
+	if n.comment != `` {
+		line += ` ` + n.comment
+	}
+	buf.WriteString(line + "\n") // nolint: errcheck
+
+	switch {
+	case n.sequence:
+		writeBody(buf, indent, n.body)
+	case n.body != nil:
+		writeBody(buf, indent+`  `, n.body)
+	case kind == `schema` && parentKind == ``:
+		// the objects of a schema are written by writeObjects
+	case n.isMapping():
+		if kind == `schema` {
+			kind = `table`
+		}
+
+		// the column descriptions of a table are text and not column definitions
+		if parentKind == `table` && n.key == `description` {
+			kind = `description`
+		}
+
+		width := 0
+		for _, c := range n.children {
+			if !c.isMapping() && c.body == nil && len(c.key) > width {
+				width = len(c.key)
+			}
+		}
+
+		for _, c := range n.children {
+			w := width
+			if c.isMapping() || c.body != nil {
+				w = 0
+			}
+			writeNode(buf, indent+`  `, c, w, kind)
+		}
+	}
+}
+
+// writeBody writes the raw lines of a sequence or block scalar at the given indentation
+func writeBody(buf *bytes.Buffer, indent string, body []string) {
+	for _, line := range body {
+		if line == `` {
+			buf.WriteString("\n") // nolint: errcheck
+			continue
+		}
+		buf.WriteString(indent + line + "\n") // nolint: errcheck
+	}
+}
+
+// definition returns the unquoted column definition
+func definition(value string) string {
+	if strings.HasPrefix(value, `'`) || strings.HasPrefix(value, `"`) {
+		var s string
+		if err := yaml.Unmarshal([]byte(value), &s); err == nil {
+			return s
+		}
+	}
+
+	return value
+}
+
+// quote quotes the definition when it isn't a plain yaml string
+func quote(def string) string {
+	out, err := yaml.Marshal(def)
+	if err != nil {
+		return def
+	}
+
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// parser splits the lines of a configuration in nodes
+type parser struct {
+	lines []string
+	// keys are the keys of the lines
+	keys   []model.KeyLine
+	pos    int
+	footer []string
+}
+
+// header returns the comments at the top of the configuration which are
+// separated from the first key by an empty line
+func (x *parser) header() []string {
+	var header []string
+	for i, line := range x.lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, `#`):
+			header = append(header, trimmed)
+		case trimmed == `` && len(header) > 0:
+			x.pos = i
+			return header
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// mapping parses the keys at the given indentation
+func (x *parser) mapping(indent int) ([]*node, error) {
+	var nodes []*node
+	var comments []string
+
+	for x.pos < len(x.lines) {
+		line := x.lines[x.pos]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == `` || trimmed == `---` {
+			x.pos++
+			continue
+		}
+
+		ind := indentation(line)
+		if strings.HasPrefix(trimmed, `#`) {
+			// comments belong to the next key, which can be a key of an outer mapping
+			if next := x.next(x.pos); next >= 0 && indentation(x.lines[next]) < indent {
+				break
+			}
+
+			comments = append(comments, trimmed)
+			x.pos++
+			continue
+		}
+
+		if ind < indent {
+			break
+		}
+
+		if ind > indent || strings.HasPrefix(trimmed, `-`) {
+			return nil, fmt.Errorf("line %d: unexpected indentation", x.pos+1)
+		}
+
+		n, err := x.node()
+		if err != nil {
+			return nil, err
+		}
+
+		n.comments = comments
+		comments = nil
+		nodes = append(nodes, n)
+	}
+
+	x.footer = append(x.footer, comments...)
+	return nodes, nil
+}
+
+// node parses the key at the current line with its value
+func (x *parser) node() (*node, error) {
+	line := x.lines[x.pos]
+	key := x.keys[x.pos]
+	if key.Key == `` {
+		return nil, fmt.Errorf("line %d: expected a key", x.pos+1)
+	}
+
+	n := &node{key: key.RawKey, value: key.Value, comment: key.Comment}
+	indent := indentation(line)
+	x.pos++
+
+	next := x.next(x.pos)
+	if next < 0 {
+		return n, nil
+	}
+
+	nextIndent := indentation(x.lines[next])
+	nextSequence := strings.HasPrefix(strings.TrimSpace(x.lines[next]), `-`)
+
+	switch {
+	case strings.HasPrefix(n.value, `|`) || strings.HasPrefix(n.value, `>`):
+		n.body = x.body(func(ind int, trimmed string) bool { return ind > indent })
+	case n.value == `` && nextSequence && nextIndent >= indent:
+		n.sequence = true
+		n.body = x.body(func(ind int, trimmed string) bool {
+			return ind > indent || (ind == indent && strings.HasPrefix(trimmed, `-`))
+		})
+	case n.value == `` && nextIndent > indent:
+		children, err := x.mapping(nextIndent)
+		if err != nil {
+			return nil, err
+		}
+		n.children = children
+	case nextIndent > indent:
+		// the continuation lines of a multi-line scalar
+		n.body = x.body(func(ind int, trimmed string) bool { return ind > indent })
+	}
+
+	return n, nil
+}
+
+// body returns the lines that belong to the current node without their common indentation
+func (x *parser) body(belongs func(indent int, trimmed string) bool) []string {
+	var lines []string
+	for x.pos < len(x.lines) {
+		line := x.lines[x.pos]
+		trimmed := strings.TrimSpace(line)
+
+		check := x.pos
+		if trimmed == `` || strings.HasPrefix(trimmed, `#`) {
+			// empty lines and comments belong to the body when the body continues after them
+			if check = x.next(x.pos); check < 0 {
+				break
+			}
+		}
+
+		if !belongs(indentation(x.lines[check]), strings.TrimSpace(x.lines[check])) {
+			break
+		}
+
+		lines = append(lines, strings.TrimRight(line, " \t"))
+		x.pos++
+	}
+
+	base := -1
+	for _, line := range lines {
+		if ind := indentation(line); line != `` && (base < 0 || ind < base) {
+			base = ind
+		}
+	}
+
+	for i, line := range lines {
+		if line != `` {
+			lines[i] = line[base:]
+		}
+	}
+
+	return lines
+}
+
+// next returns the index of the first line from i which isn't empty or a comment, or -1
+func (x *parser) next(i int) int {
+	for ; i < len(x.lines); i++ {
+		if trimmed := strings.TrimSpace(x.lines[i]); trimmed != `` && trimmed != `---` && !strings.HasPrefix(trimmed, `#`) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// indentation returns the number of leading spaces of the line
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, ` `))
+}
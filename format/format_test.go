@@ -0,0 +1,120 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/myceliums/assert"
+)
+
+const unformatted = `# example configuration
+
+# posts of the accounts
+posts:
+  id: serial primarykey
+  author: accounts.id unique(author_title) notnull # the writer
+  title: "varchar(100) not null unique(author_title)"
+  kind: post_kind default('note')
+  seed:
+  - id: 1
+    # the first post
+    author: 1
+    title: hello
+
+accounts:
+  id: int auto increment primary key
+  name: varchar(20) not null
+
+
+post_kind: [note, article]
+
+auth:
+  sessions:
+    id: int primary
+    account: auth.users.id not null
+  users:
+    id: int primary
+  active:
+    view: |
+      SELECT id
+        FROM auth.sessions
+    id: int
+
+# end of the configuration
+`
+
+const formatted = `# example configuration
+
+post_kind: [note, article]
+
+accounts:
+  id:   int primary auto increment
+  name: varchar(20) not null
+
+# posts of the accounts
+posts:
+  id:     serial primary
+  author: accounts.id unique(author_title) not null # the writer
+  title:  varchar(100) unique(author_title) not null
+  kind:   post_kind default('note')
+  seed:
+  - id: 1
+    # the first post
+    author: 1
+    title: hello
+
+auth:
+  users:
+    id: int primary
+
+  sessions:
+    id:      int primary
+    account: auth.users.id not null
+
+  active:
+    view: |
+      SELECT id
+        FROM auth.sessions
+    id: int
+
+# end of the configuration
+`
+
+func TestSource(t *testing.T) {
+	as := assert.New(t)
+
+	out, err := Source([]byte(unformatted), Options{})
+	as.NoError(err)
+	as.Eq(formatted, string(out))
+
+	out, err = Source(out, Options{})
+	as.NoError(err)
+	as.Eq(formatted, string(out))
+
+	out, err = Source([]byte("b:\n  id: int primary\n  a: a.id\na:\n  id: int primary\n"), Options{Order: Name})
+	as.NoError(err)
+	as.Eq("a:\n  id: int primary\n\nb:\n  id: int primary\n  a:  a.id\n", string(out))
+
+	out, err = Source([]byte("a:\n  id: int primary\n  description:\n    id: not null unique\n"), Options{})
+	as.NoError(err)
+	as.Eq("a:\n  id: int primary\n  description:\n    id: not null unique\n", string(out))
+
+	// a column named view is formatted like the other columns
+	out, err = Source([]byte("pages:\n  id: int primary key\n  view: int notnull\n"), Options{})
+	as.NoError(err)
+	as.Eq("pages:\n  id:   int primary\n  view: int not null\n", string(out))
+
+	_, err = Source([]byte("a:\n  id: int\n   b: int\n"), Options{})
+	as.Error(err)
+
+	_, err = Source([]byte("a:\n  id: int\n"), Options{Order: `random`})
+	as.Error(err)
+}
+
+func TestNormalize(t *testing.T) {
+	as := assert.New(t)
+
+	as.Eq(`int primary`, normalize(`int primary key`))
+	as.Eq(`int primary auto increment not null`, normalize(`int notnull autoincrement primarykey`))
+	as.Eq(`varchar(10) unique(a) not null default('a b') check(length(x)>1)`, normalize(`varchar(10) check(length(x)>1) default('a b') not null unique(a)`))
+	as.Eq(`timestamp default(NOW()) collate x`, normalize(`timestamp collate x default(NOW())`))
+}
@@ -0,0 +1,127 @@
+// Package gdbtest contains helpers to test the sql of models against golden files,
+// which are checked in snapshots of the expected sql. Run the tests with -update to
+// rewrite the golden files after an intended change and review them in the diff:
+//
+//	func TestMigrations(t *testing.T) {
+//		prev := gdbtest.Model(t, `accounts: {id: serial primary}`)
+//		curr := gdbtest.Model(t, `accounts: {id: serial primary, name: text}`)
+//
+//		gdbtest.UpgradeSQL(t, dialect.GetByDriver(`postgres`), prev, curr, `testdata/add_name.sql`)
+//	}
+//
+// The package defines the -update flag, so a test package using it can't define its own.
+package gdbtest
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/gdb/dialect"
+	"github.com/myceliums/gdb/model"
+)
+
+var update = flag.Bool(`update`, false, `rewrites the golden files of gdbtest instead of comparing them`)
+
+// Model returns the model of the configuration or fails the test when it's invalid
+func Model(t testing.TB, config string) model.Model {
+	t.Helper()
+
+	mdl, err := model.New([]byte(config))
+	if err != nil {
+		t.Fatalf("invalid configuration: %v", err)
+	}
+
+	return *mdl
+}
+
+// InitialSQL compares the sql which creates the model in an empty database with the golden file
+func InitialSQL(t testing.TB, dia dialect.Dialect, mdl model.Model, golden string) {
+	t.Helper()
+	Golden(t, golden, model.InitialSQL(dia, mdl))
+}
+
+// UpgradeSQL compares the sql which migrates the database from prev to curr with the golden file
+func UpgradeSQL(t testing.TB, dia dialect.Dialect, prev, curr model.Model, golden string) {
+	t.Helper()
+	Golden(t, golden, model.UpgradeSQL(dia, prev, curr))
+}
+
+// Golden compares the actual output with the content of the golden file and reports
+// the differing lines, with -update the golden file is written with the actual output
+func Golden(t testing.TB, golden, actual string) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatalf("writing golden file %s: %v", golden, err)
+			return
+		}
+
+		if err := ioutil.WriteFile(golden, []byte(actual), 0644); err != nil {
+			t.Fatalf("writing golden file %s: %v", golden, err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	switch {
+	case os.IsNotExist(err):
+		t.Fatalf("golden file %s doesn't exist, run the test with -update to write it", golden)
+		return
+	case err != nil:
+		t.Fatalf("reading golden file %s: %v", golden, err)
+		return
+	}
+
+	if string(expected) != actual {
+		t.Errorf("output differs from golden file %s, run the test with -update to rewrite it:\n%s", golden, diff(string(expected), actual))
+	}
+}
+
+// diff returns the lines of the longest common subsequence of both texts
+// unprefixed, the removed lines of expected prefixed with - and the added
+// lines of actual prefixed with +
+func diff(expected, actual string) string {
+	a, b := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	builder := &strings.Builder{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			builder.WriteString("  " + a[i] + "\n") // nolint: errcheck
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			builder.WriteString("- " + a[i] + "\n") // nolint: errcheck
+			i++
+		default:
+			builder.WriteString("+ " + b[j] + "\n") // nolint: errcheck
+			j++
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,77 @@
+package gdbtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/dialect"
+)
+
+// recorder records the failures of a test
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (x *recorder) Helper() {}
+
+func (x *recorder) Errorf(format string, args ...interface{}) {
+	x.errors = append(x.errors, format)
+}
+
+func (x *recorder) Fatalf(format string, args ...interface{}) {
+	x.errors = append(x.errors, format)
+	x.fatal = true
+}
+
+func TestGolden(t *testing.T) {
+	as := assert.New(t)
+
+	dir, err := ioutil.TempDir(``, `gdbtest`)
+	as.NoError(err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	golden := filepath.Join(dir, `testdata`, `accounts.sql`)
+	mdl := Model(t, "accounts:\n  id: serial primary\n  name: text not null\n")
+	dia := dialect.GetByDriver(`postgres`)
+
+	rec := &recorder{TB: t}
+	InitialSQL(rec, dia, mdl, golden)
+	as.True(rec.fatal)
+
+	*update = true
+	InitialSQL(t, dia, mdl, golden)
+	*update = false
+
+	rec = &recorder{TB: t}
+	InitialSQL(rec, dia, Model(t, "accounts:\n  name: text not null\n  id: serial primary\n"), golden)
+	as.Eq(0, len(rec.errors))
+
+	out, err := ioutil.ReadFile(golden)
+	as.NoError(err)
+	as.Eq(`CREATE TABLE "accounts"();
+ALTER TABLE "accounts" ADD COLUMN "id" INT;
+CREATE SEQUENCE "seq_accounts_id" OWNED BY "accounts"."id";
+SELECT setval('"seq_accounts_id"', (SELECT max("id") FROM "accounts"));
+ALTER TABLE "accounts" ALTER COLUMN "id" SET DEFAULT nextval('"seq_accounts_id"'::regclass);
+ALTER TABLE "accounts" ADD COLUMN "name" TEXT;
+ALTER TABLE "accounts" ALTER COLUMN "name" SET NOT NULL;
+ALTER TABLE "accounts" ADD CONSTRAINT "pk_accounts" PRIMARY KEY("id");
+`, string(out))
+
+	rec = &recorder{TB: t}
+	UpgradeSQL(rec, dia, mdl, mdl, golden)
+	as.Eq(1, len(rec.errors))
+	as.False(rec.fatal)
+}
+
+func TestDiff(t *testing.T) {
+	as := assert.New(t)
+
+	as.Eq("  a\n- b\n+ x\n  c\n+ d\n", diff("a\nb\nc", "a\nx\nc\nd"))
+	as.Eq("  a\n", diff("a", "a"))
+}
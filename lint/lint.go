@@ -0,0 +1,228 @@
+// Package lint checks a configuration for design mistakes which are valid for
+// the model, like tables without a primary key or nullable booleans
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/myceliums/gdb/model"
+)
+
+// Severity is the level of a diagnostic
+type Severity string
+
+const (
+	// Error is a diagnostic that should fail the lint
+	Error Severity = `error`
+
+	// Warning is a diagnostic that's reported but doesn't fail the lint
+	Warning Severity = `warning`
+
+	// Off disables the rule
+	Off Severity = `off`
+)
+
+// Diagnostic is a violation of a rule by an object of the configuration
+type Diagnostic struct {
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Object   string   `json:"object"`
+	Message  string   `json:"message"`
+}
+
+// String returns the diagnostic as file:line: severity: message (rule)
+func (x Diagnostic) String() string {
+	pos := x.File
+	if pos != `` && x.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", pos, x.Line)
+	}
+
+	if pos == `` {
+		return fmt.Sprintf("%s: %s (%s)", x.Severity, x.Message, x.Rule)
+	}
+
+	return fmt.Sprintf("%s: %s: %s (%s)", pos, x.Severity, x.Message, x.Rule)
+}
+
+// Config configures the rules, it's read from a yaml file like:
+//
+//	disable:
+//	- plural-tables
+//	severity:
+//	  missing-created-at: error
+//	created_at: inserted_at
+type Config struct {
+	// Disable contains the names of the rules that are skipped
+	Disable []string `yaml:"disable"`
+	// Severity overrides the default severity of the rules
+	Severity map[string]Severity `yaml:"severity"`
+	// CreatedAt is the column every table should have, the default is created_at
+	CreatedAt string `yaml:"created_at"`
+	// ReservedWords are checked in addition to the reserved words of sql
+	ReservedWords []string `yaml:"reserved_words"`
+}
+
+// ReadConfig reads the rule configuration of the given file
+func ReadConfig(path string) (Config, error) {
+	var x Config
+
+	in, err := ioutil.ReadFile(path)
+	if err != nil {
+		return x, err
+	}
+
+	if err := yaml.UnmarshalStrict(in, &x); err != nil {
+		return x, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return x, x.Validate()
+}
+
+// Validate checks that the configuration only refers to known rules and severities
+func (x Config) Validate() error {
+	known := map[string]bool{}
+	for _, rule := range Rules() {
+		known[rule.Name] = true
+	}
+
+	for _, name := range x.Disable {
+		if !known[name] {
+			return fmt.Errorf("unknown rule %s", name)
+		}
+	}
+
+	for name, severity := range x.Severity {
+		if !known[name] {
+			return fmt.Errorf("unknown rule %s", name)
+		}
+
+		switch severity {
+		case Error, Warning, Off:
+		default:
+			return fmt.Errorf("rule %s has an unknown severity %s, want error, warning or off", name, severity)
+		}
+	}
+
+	return nil
+}
+
+// severity returns the configured severity of the rule
+func (x Config) severity(rule Rule) Severity {
+	for _, name := range x.Disable {
+		if name == rule.Name {
+			return Off
+		}
+	}
+
+	if severity, ok := x.Severity[rule.Name]; ok {
+		return severity
+	}
+
+	return rule.Severity
+}
+
+// Rule checks the model for a single kind of mistake
+type Rule struct {
+	Name        string
+	Description string
+	// Severity is the default severity of the diagnostics of the rule
+	Severity Severity
+	// Check reports every object which violates the rule
+	Check func(mdl model.Model, conf Config, report Report)
+}
+
+// Report reports a violation of the rule by the given object
+type Report func(object, format string, args ...interface{})
+
+// Lint checks the model with the enabled rules and returns the diagnostics
+// sorted by their position, objects without a position are sorted last
+func Lint(mdl model.Model, conf Config, positions Positions) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	for _, rule := range Rules() {
+		severity := conf.severity(rule)
+		if severity == Off {
+			continue
+		}
+
+		rule.Check(mdl, conf, func(object, format string, args ...interface{}) {
+			pos := positions.Lookup(object)
+			diagnostics = append(diagnostics, Diagnostic{
+				File:     pos.File,
+				Line:     pos.Line,
+				Rule:     rule.Name,
+				Severity: severity,
+				Object:   object,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		})
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		switch {
+		case (a.File == ``) != (b.File == ``):
+			return a.File != ``
+		case a.File != b.File:
+			return a.File < b.File
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		case a.Object != b.Object:
+			return a.Object < b.Object
+		}
+
+		return a.Rule < b.Rule
+	})
+
+	return diagnostics
+}
+
+// HasErrors returns true when one of the diagnostics is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tableNames returns the sorted names of the tables of the model
+func tableNames(mdl model.Model) []string {
+	var names []string
+	for name := range mdl.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// columns returns the columns of the table sorted by name
+func columns(mdl model.Model, table string) []*model.Column {
+	var cols []*model.Column
+	for _, col := range mdl.Tables[table] {
+		cols = append(cols, col)
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].Name < cols[j].Name })
+
+	return cols
+}
+
+// columnName returns the schema qualified name of the column
+func columnName(col *model.Column) string {
+	return col.Table + `.` + col.Name
+}
+
+// unqualified returns the name without its schema
+func unqualified(name string) string {
+	return name[strings.LastIndex(name, `.`)+1:]
+}
@@ -0,0 +1,114 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/model"
+)
+
+var config = []byte(`# accounts and their posts
+accounts:
+  id: int primary
+  email: varchar(100) unique(email)
+  active: bool
+  created_at: timestamp
+  seed:
+  - id: 1
+    email: hello@example.com
+
+post:
+  id: int primary
+  author: accounts.id not null
+  kind: post_kind
+  title: varchar
+  created_at: timestamp
+
+log:
+  message: text
+  user: varchar(20)
+
+post_kind:
+- article
+- note
+`)
+
+func TestLint(t *testing.T) {
+	as := assert.New(t)
+
+	mdl, err := model.New(config)
+	as.NoError(err)
+
+	diagnostics := Lint(*mdl, Config{}, NewPositions(`db.yml`, config))
+
+	var lines []string
+	for _, d := range diagnostics {
+		lines = append(lines, d.String())
+	}
+
+	as.Cmp([]string{
+		`db.yml:5: warning: boolean column accounts.active is nullable (nullable-boolean)`,
+		`db.yml:11: warning: table name post is not plural (plural-tables)`,
+		`db.yml:13: warning: foreign key post.author has no index (foreign-key-index)`,
+		`db.yml:14: warning: enum column post.kind has no default (enum-default)`,
+		`db.yml:15: warning: varchar column post.title has no size (varchar-size)`,
+		`db.yml:18: warning: table log has no created_at column (missing-created-at)`,
+		`db.yml:18: error: table log has no primary key (no-primary-key)`,
+		`db.yml:18: warning: table name log is not plural (plural-tables)`,
+		`db.yml:20: error: column name user is a reserved word (reserved-words)`,
+	}, lines)
+	as.True(HasErrors(diagnostics))
+
+	conf := Config{
+		Disable:   []string{`plural-tables`, `no-primary-key`},
+		Severity:  map[string]Severity{`reserved-words`: Warning, `varchar-size`: Off},
+		CreatedAt: `message`,
+	}
+	as.NoError(conf.Validate())
+
+	diagnostics = Lint(*mdl, conf, nil)
+	as.False(HasErrors(diagnostics))
+	for _, d := range diagnostics {
+		as.Ne(`plural-tables`, d.Rule)
+		as.Ne(`varchar-size`, d.Rule)
+		as.Eq(0, d.Line)
+	}
+	as.Eq(6, len(diagnostics))
+
+	as.Error(Config{Disable: []string{`unknown`}}.Validate())
+	as.Error(Config{Severity: map[string]Severity{`snake-case`: `fatal`}}.Validate())
+}
+
+func TestPositionsFromFiles(t *testing.T) {
+	as := assert.New(t)
+
+	dir, err := ioutil.TempDir(``, `gdb-lint`)
+	as.NoError(err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	main, included := filepath.Join(dir, `db.yml`), filepath.Join(dir, `auth.yml`)
+	as.NoError(ioutil.WriteFile(main, []byte("include: auth.yml\n\naccounts:\n  id: int primary\n"), 0644))
+	as.NoError(ioutil.WriteFile(included, []byte(`auth:
+  sessions:
+    id: int primary
+    query: |
+      id: not a key
+  active_sessions:
+    view: |
+      SELECT id
+      FROM sessions
+`), 0644))
+
+	positions, err := PositionsFromFiles(main)
+	as.NoError(err)
+
+	as.Eq(Position{main, 3}, positions.Lookup(`accounts`))
+	as.Eq(Position{main, 4}, positions.Lookup(`accounts.id`))
+	as.Eq(Position{included, 2}, positions.Lookup(`auth.sessions`))
+	as.Eq(Position{included, 3}, positions.Lookup(`auth.sessions.id`))
+	as.Eq(Position{included, 6}, positions.Lookup(`auth.active_sessions`))
+	as.Eq(Position{}, positions.Lookup(`auth.sessions.query.id`))
+}
@@ -0,0 +1,61 @@
+package lint
+
+import (
+	"io/ioutil"
+
+	"github.com/myceliums/gdb/model"
+)
+
+// Position is the place of an object in a configuration file
+type Position struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// Positions maps the schema qualified names of the objects of a configuration
+// to their position, columns are stored as <table>.<column>
+type Positions map[string]Position
+
+// Lookup returns the position of the object, or an empty position when it's unknown
+func (x Positions) Lookup(object string) Position {
+	return x[object]
+}
+
+// NewPositions returns the positions of the keys of the given yaml document
+func NewPositions(file string, in []byte) Positions {
+	x := Positions{}
+	for i, line := range model.KeyLines(in) {
+		if name := line.Object(); name != `` {
+			if _, ok := x[name]; !ok {
+				x[name] = Position{File: file, Line: i + 1}
+			}
+		}
+	}
+
+	return x
+}
+
+// PositionsFromFiles returns the positions of the objects in the given
+// configuration files and directories, including the files they include
+func PositionsFromFiles(paths ...string) (Positions, error) {
+	files, err := model.Files(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	x := Positions{}
+	for _, file := range files {
+		in, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, pos := range NewPositions(file, in) {
+			if _, ok := x[name]; !ok {
+				x[name] = pos
+			}
+		}
+	}
+
+	return x, nil
+}
@@ -0,0 +1,233 @@
+package lint
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/myceliums/gdb/model"
+)
+
+var (
+	// snakeCaseReg matches lower case words separated by a single underscore
+	snakeCaseReg = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+	// uncountables are words that are plural without ending on an s
+	uncountables = map[string]bool{
+		`data`: true, `metadata`: true, `media`: true, `people`: true, `children`: true,
+		`information`: true, `equipment`: true, `feedback`: true, `staff`: true, `criteria`: true,
+	}
+
+	// reservedWords are the words that can only be used as identifier when they're quoted
+	reservedWords = map[string]bool{}
+)
+
+func init() {
+	for _, word := range strings.Fields(`all analyse analyze and any array as asc asymmetric
+		authorization binary both case cast check collate column concurrently constraint create
+		cross current_catalog current_date current_role current_schema current_time current_timestamp
+		current_user default deferrable desc distinct do else end except false fetch for foreign
+		freeze from full grant group having ilike in initially inner intersect into is isnull join
+		lateral leading left like limit localtime localtimestamp natural not notnull null offset on
+		only or order outer overlaps placing primary references returning right select session_user
+		similar some symmetric table tablesample then to trailing true union unique user using
+		variadic verbose when where window with`) {
+		reservedWords[word] = true
+	}
+}
+
+// Rules returns all rules in the order they're checked
+func Rules() []Rule {
+	return []Rule{
+		{
+			Name:        `no-primary-key`,
+			Description: `tables should have a primary key`,
+			Severity:    Error,
+			Check:       checkPrimaryKey,
+		},
+		{
+			Name:        `foreign-key-index`,
+			Description: `foreign key columns should be indexed by a primary key or unique constraint`,
+			Severity:    Warning,
+			Check:       checkForeignKeyIndex,
+		},
+		{
+			Name:        `varchar-size`,
+			Description: `varchar columns should have a size`,
+			Severity:    Warning,
+			Check:       checkVarcharSize,
+		},
+		{
+			Name:        `nullable-boolean`,
+			Description: `boolean columns should be not null`,
+			Severity:    Warning,
+			Check:       checkNullableBoolean,
+		},
+		{
+			Name:        `enum-default`,
+			Description: `enum columns should have a default`,
+			Severity:    Warning,
+			Check:       checkEnumDefault,
+		},
+		{
+			Name:        `snake-case`,
+			Description: `names should be snake_case`,
+			Severity:    Error,
+			Check:       checkSnakeCase,
+		},
+		{
+			Name:        `plural-tables`,
+			Description: `table names should be plural`,
+			Severity:    Warning,
+			Check:       checkPluralTables,
+		},
+		{
+			Name:        `reserved-words`,
+			Description: `names should not be reserved words`,
+			Severity:    Error,
+			Check:       checkReservedWords,
+		},
+		{
+			Name:        `missing-created-at`,
+			Description: `tables should have a created_at column`,
+			Severity:    Warning,
+			Check:       checkCreatedAt,
+		},
+	}
+}
+
+func checkPrimaryKey(mdl model.Model, conf Config, report Report) {
+	for _, table := range tableNames(mdl) {
+		if len(mdl.Primaries[table]) == 0 {
+			report(table, "table %s has no primary key", table)
+		}
+	}
+}
+
+// checkForeignKeyIndex reports foreign keys which aren't part of the primary key
+// or a unique constraint, these are the only constraints that create an index
+func checkForeignKeyIndex(mdl model.Model, conf Config, report Report) {
+	for _, table := range tableNames(mdl) {
+		for _, col := range columns(mdl, table) {
+			if col.Ref != nil && col.Primary == `` && col.Unique == `` {
+				report(columnName(col), "foreign key %s has no index", columnName(col))
+			}
+		}
+	}
+}
+
+func checkVarcharSize(mdl model.Model, conf Config, report Report) {
+	for _, table := range tableNames(mdl) {
+		for _, col := range columns(mdl, table) {
+			if col.Ref == nil && col.Type() == `varchar` && col.Size == 0 {
+				report(columnName(col), "varchar column %s has no size", columnName(col))
+			}
+		}
+	}
+}
+
+func checkNullableBoolean(mdl model.Model, conf Config, report Report) {
+	for _, table := range tableNames(mdl) {
+		for _, col := range columns(mdl, table) {
+			if col.Ref == nil && col.Type() == `boolean` && !col.NotNull && col.Primary == `` {
+				report(columnName(col), "boolean column %s is nullable", columnName(col))
+			}
+		}
+	}
+}
+
+func checkEnumDefault(mdl model.Model, conf Config, report Report) {
+	for _, table := range tableNames(mdl) {
+		for _, col := range columns(mdl, table) {
+			if _, ok := col.Datatype.(*model.Enum); ok && col.Default == `` {
+				report(columnName(col), "enum column %s has no default", columnName(col))
+			}
+		}
+	}
+}
+
+func checkSnakeCase(mdl model.Model, conf Config, report Report) {
+	eachName(mdl, func(kind, object string) {
+		if name := unqualified(object); !snakeCaseReg.MatchString(name) {
+			report(object, "%s name %s is not snake_case", kind, name)
+		}
+	})
+}
+
+func checkPluralTables(mdl model.Model, conf Config, report Report) {
+	for _, table := range tableNames(mdl) {
+		if name := unqualified(table); !plural(name) {
+			report(table, "table name %s is not plural", name)
+		}
+	}
+}
+
+func checkReservedWords(mdl model.Model, conf Config, report Report) {
+	reserved := map[string]bool{}
+	for _, word := range conf.ReservedWords {
+		reserved[strings.ToLower(word)] = true
+	}
+
+	eachName(mdl, func(kind, object string) {
+		if name := unqualified(object); reservedWords[name] || reserved[name] {
+			report(object, "%s name %s is a reserved word", kind, name)
+		}
+	})
+}
+
+func checkCreatedAt(mdl model.Model, conf Config, report Report) {
+	name := conf.CreatedAt
+	if name == `` {
+		name = `created_at`
+	}
+
+	for _, table := range tableNames(mdl) {
+		if _, ok := mdl.Tables[table][name]; !ok {
+			report(table, "table %s has no %s column", table, name)
+		}
+	}
+}
+
+// eachName calls fn with the kind and qualified name of every schema, enum,
+// table, column and view of the model in a stable order
+func eachName(mdl model.Model, fn func(kind, object string)) {
+	for _, schema := range mdl.Schemas {
+		fn(`schema`, schema)
+	}
+
+	var enums []string
+	for name := range mdl.Enums {
+		enums = append(enums, name)
+	}
+	sort.Strings(enums)
+	for _, name := range enums {
+		fn(`enum`, name)
+	}
+
+	for _, table := range tableNames(mdl) {
+		fn(`table`, table)
+		for _, col := range columns(mdl, table) {
+			fn(`column`, columnName(col))
+		}
+	}
+
+	var views []string
+	for name := range mdl.Views {
+		views = append(views, name)
+	}
+	sort.Strings(views)
+	for _, name := range views {
+		fn(`view`, name)
+	}
+}
+
+// plural guesses whether the last word of the name is plural
+func plural(name string) bool {
+	word := name[strings.LastIndex(name, `_`)+1:]
+	if uncountables[word] {
+		return true
+	}
+
+	return strings.HasSuffix(word, `s`) && !strings.HasSuffix(word, `ss`) &&
+		!strings.HasSuffix(word, `us`) && !strings.HasSuffix(word, `is`)
+}
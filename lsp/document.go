@@ -0,0 +1,349 @@
+package lsp
+
+import (
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/myceliums/gdb/model"
+)
+
+var (
+	// typeTokenReg matches the type at the start of a column definition
+	typeTokenReg = regexp.MustCompile(`^[\w\-.]+`)
+
+	// yamlLineReg matches the line of a yaml syntax error
+	yamlLineReg = regexp.MustCompile(`line (\d+)`)
+)
+
+// The keys of a configuration which aren't columns
+var specialKeys = map[string]bool{
+	`view`:         true,
+	`materialized`: true,
+	`enum`:         true,
+	`recreate`:     true,
+	`include`:      true,
+	`seed`:         true,
+	`description`:  true,
+}
+
+// line is a scanned line of a configuration
+type line struct {
+	model.KeyLine
+}
+
+// scan returns the lines of the document with the keys they're nested in
+func scan(text string) []line {
+	var x []line
+	for _, l := range model.KeyLines([]byte(text)) {
+		x = append(x, line{l})
+	}
+
+	return x
+}
+
+// isColumn returns true when the line looks like the definition of a column,
+// which is a key with a scalar value nested in a table
+func (x line) isColumn() bool {
+	if x.Key == `` || len(x.Path) < 2 || x.Object() == `` {
+		return false
+	}
+
+	// a column named description has a string value instead of a mapping, a column
+	// named view has a type instead of a query and one named materialized instead of a bool
+	switch {
+	case x.Key == descriptionKey && x.Value != ``:
+	case x.Key == `view` && x.Value != `` && !model.IsQuery(strings.Trim(x.Value, `"'`)):
+	case x.Key == `materialized` && x.Value != `` && x.Value != `true` && x.Value != `false`:
+	case specialKeys[x.Key]:
+		return false
+	}
+
+	if parent := x.Path[len(x.Path)-2]; parent == descriptionKey || parent == `seed` {
+		return false
+	}
+
+	return !strings.HasPrefix(x.Value, `|`) && !strings.HasPrefix(x.Value, `>`) && !strings.HasPrefix(x.Value, `[`) && !strings.HasPrefix(x.Value, `{`)
+}
+
+// offset returns the byte offset in the text of the line of the utf-16 character
+// offset of a position, the offset is at most the length of the text
+func (x line) offset(character int) int {
+	n := 0
+	for i, r := range x.Text {
+		if n >= character {
+			return i
+		}
+
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+
+	return len(x.Text)
+}
+
+// character returns the utf-16 character offset of the byte offset in the text of the line
+func (x line) character(offset int) int {
+	if offset > len(x.Text) {
+		offset = len(x.Text)
+	}
+
+	n := 0
+	for _, r := range x.Text[:offset] {
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+
+	return n
+}
+
+// span returns the range of line i from the start to the end byte offset
+func (x line) span(i, start, end int) textRange {
+	return textRange{Start: position{Line: i, Character: x.character(start)}, End: position{Line: i, Character: x.character(end)}}
+}
+
+// descriptionKey is the key of the column descriptions of a table
+const descriptionKey = `description`
+
+// document is an opened configuration
+type document struct {
+	uri   string
+	path  string
+	text  string
+	lines []line
+	// model is the last valid model of the document, which is used while the document is being edited
+	model *model.Model
+	err   error
+}
+
+// newDocument parses the text of the document, the model of a document with
+// includes is loaded from the files on disk
+func newDocument(uri, text string, prev *document) *document {
+	x := &document{uri: uri, path: uriToPath(uri), text: text, lines: scan(text)}
+	if prev != nil {
+		x.model = prev.model
+	}
+
+	var mdl *model.Model
+	if x.includes() && x.path != `` {
+		mdl, x.err = model.NewFromFiles(x.path)
+	} else {
+		mdl, x.err = model.New([]byte(text))
+	}
+
+	if x.err == nil {
+		x.model = mdl
+	}
+
+	return x
+}
+
+// includes returns true when the document includes other files
+func (x *document) includes() bool {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(x.text), &doc); err != nil {
+		return false
+	}
+
+	_, ok := doc[`include`]
+	return ok
+}
+
+// source is the text of a configuration file, which is an opened document or a file on disk
+type source struct {
+	uri   string
+	lines []line
+}
+
+// sources returns the document and the files it includes, opened documents
+// are used instead of the files on disk
+func (x *document) sources(opened map[string]*document) []source {
+	list := []source{{uri: x.uri, lines: x.lines}}
+	if !x.includes() || x.path == `` {
+		return list
+	}
+
+	included, err := model.Files(x.path)
+	if err != nil {
+		return list
+	}
+
+	files := map[string]bool{}
+	for _, file := range included {
+		if abs, err := filepath.Abs(file); err == nil && abs != x.path {
+			files[abs] = true
+		}
+	}
+
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		uri := pathToURI(path)
+		if doc, ok := opened[uri]; ok {
+			list = append(list, source{uri: uri, lines: doc.lines})
+			continue
+		}
+
+		in, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		list = append(list, source{uri: uri, lines: scan(string(in))})
+	}
+
+	return list
+}
+
+// schema returns the schema the line is nested in
+func (x *document) schema(l line) string {
+	if x.model == nil || len(l.Path) < 1 {
+		return ``
+	}
+
+	for _, schema := range x.model.Schemas {
+		if schema == l.Path[0] {
+			return schema
+		}
+	}
+
+	return ``
+}
+
+// resolve returns the table, enum, view or table.column the name refers to, names
+// inside of a schema are resolved in that schema first like the model does
+func (x *document) resolve(schema, name string) string {
+	if x.model == nil || name == `` {
+		return ``
+	}
+
+	candidates := []string{name}
+	if schema != `` {
+		candidates = []string{schema + `.` + name, name}
+	}
+
+	for _, candidate := range candidates {
+		if x.model.Tables[candidate] != nil || x.model.Enums[candidate] != nil || x.model.Views[candidate] != nil {
+			return candidate
+		}
+
+		if col := x.column(candidate); col != nil {
+			return candidate
+		}
+	}
+
+	return ``
+}
+
+// column returns the column of the given table.column
+func (x *document) column(object string) *model.Column {
+	if x.model == nil {
+		return nil
+	}
+
+	i := strings.LastIndex(object, `.`)
+	if i < 0 {
+		return nil
+	}
+
+	return x.model.Tables[object[:i]][object[i+1:]]
+}
+
+// objectAt returns the object at the position, which is the object of the key
+// or the column description or seed key at the position, or the type the value
+// at the position refers to
+func (x *document) objectAt(pos position) string {
+	if pos.Line < 0 || pos.Line >= len(x.lines) {
+		return ``
+	}
+
+	l := x.lines[pos.Line]
+	if l.Key == `` {
+		return ``
+	}
+
+	offset := l.offset(pos.Character)
+	if offset < l.ValueStart {
+		return x.keyObject(l)
+	}
+
+	if !l.isColumn() {
+		return ``
+	}
+
+	// the name ends with the part at the position, so the table of table.column can be resolved
+	start, end := offset, offset
+	for start > 0 && isNameChar(l.Text[start-1]) {
+		start--
+	}
+	for end < len(l.Text) && isNameChar(l.Text[end]) && l.Text[end] != '.' {
+		end++
+	}
+	if start >= end {
+		return ``
+	}
+
+	return x.resolve(x.schema(l), l.Text[start:end])
+}
+
+// keyObject returns the object the key of the line defines, the keys of the
+// description mapping and of the seed rows of a table refer to its columns
+func (x *document) keyObject(l line) string {
+	if object := l.Object(); object != `` {
+		if n := len(l.Path); n > 2 && l.Path[n-2] == descriptionKey {
+			return x.resolve(``, strings.Join(append(l.Path[:n-2:n-2], l.Key), `.`))
+		}
+
+		return x.resolve(``, object)
+	}
+
+	if n := len(l.Path); n > 3 && l.Path[n-2] == `-` && l.Path[n-3] == `seed` {
+		return x.resolve(``, strings.Join(append(l.Path[:n-3:n-3], l.Key), `.`))
+	}
+
+	return ``
+}
+
+// definition returns the source and line of the key of the object
+func (x *document) definition(sources []source, object string) (source, int, bool) {
+	for _, src := range sources {
+		for i, l := range src.lines {
+			if l.Object() == object {
+				return src, i, true
+			}
+		}
+	}
+
+	return source{}, 0, false
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// uriToPath returns the path of a file uri, or an empty string for other uris
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != `file` {
+		return ``
+	}
+
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI returns the file uri of the absolute path
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: `file`, Path: filepath.ToSlash(path)}).String()
+}
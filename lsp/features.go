@@ -0,0 +1,367 @@
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/myceliums/gdb/model"
+)
+
+// wordReg matches the names in an error message
+var wordReg = regexp.MustCompile(`[\w.\-]+`)
+
+// The modifiers of a column definition
+var modifiers = []string{`primary`, `auto increment`, `unique`, `not null`, `default()`, `check()`, `using()`}
+
+// diagnostics returns the error of the model of the document as diagnostic, the
+// error is shown at the line of the yaml syntax error or at the deepest key of
+// which the name or all its parts are part of the error message
+func (x *document) diagnostics() []diagnostic {
+	list := []diagnostic{}
+	if x.err == nil {
+		return list
+	}
+
+	i := x.errorLine()
+	var r textRange
+	if i < len(x.lines) {
+		r = lineRange(x.lines[i], i)
+	}
+
+	return append(list, diagnostic{Range: r, Severity: severityError, Source: `gdb`, Message: x.err.Error()})
+}
+
+// errorLine returns the zero based line of the error
+func (x *document) errorLine() int {
+	msg := x.err.Error()
+	if strings.HasPrefix(msg, `yaml:`) {
+		if match := yamlLineReg.FindStringSubmatch(msg); match != nil {
+			if n, err := strconv.Atoi(match[1]); err == nil && n > 0 {
+				return n - 1
+			}
+		}
+		return 0
+	}
+
+	words := map[string]bool{}
+	for _, word := range wordReg.FindAllString(msg, -1) {
+		words[word] = true
+	}
+
+	best, depth := 0, 0
+	for i, l := range x.lines {
+		object := l.Object()
+		if object == `` || len(l.Path) <= depth {
+			continue
+		}
+
+		found := words[object]
+		if !found {
+			found = true
+			for _, part := range l.Path {
+				found = found && words[part]
+			}
+		}
+
+		if found {
+			best, depth = i, len(l.Path)
+		}
+	}
+
+	return best
+}
+
+// completion returns the types, enums and referenced columns at the start of a
+// column definition and the modifiers after its type
+func (x *Server) completion(params positionParams) []completionItem {
+	items := []completionItem{}
+
+	doc := x.documents[params.TextDocument.URI]
+	if doc == nil || params.Position.Line >= len(doc.lines) {
+		return items
+	}
+
+	l := doc.lines[params.Position.Line]
+	end := l.offset(params.Position.Character)
+	if !l.isColumn() || end < l.ValueStart {
+		return items
+	}
+
+	// the modifiers follow the type of the column
+	if strings.ContainsAny(strings.TrimLeft(l.Text[l.ValueStart:end], " \t"), " \t") {
+		for _, m := range modifiers {
+			items = append(items, completionItem{Label: m, Kind: completionKeyword})
+		}
+		return items
+	}
+
+	for _, name := range model.PrimitiveTypes() {
+		items = append(items, completionItem{Label: name, Kind: completionTypeParameter, Detail: x.dialect.Type(name, 0)})
+	}
+
+	mdl := doc.model
+	if mdl == nil {
+		return items
+	}
+
+	var enums []string
+	for name := range mdl.Enums {
+		enums = append(enums, name)
+	}
+	sort.Strings(enums)
+
+	for _, name := range enums {
+		items = append(items, completionItem{Label: name, Kind: completionEnum, Detail: `enum ` + strings.Join(mdl.Enums[name].Values, `, `)})
+	}
+
+	var columns []string
+	for table, cols := range mdl.Tables {
+		for name := range cols {
+			columns = append(columns, table+`.`+name)
+		}
+	}
+	sort.Strings(columns)
+
+	for _, name := range columns {
+		items = append(items, completionItem{Label: name, Kind: completionField, Detail: x.sqlType(doc.column(name))})
+	}
+
+	return items
+}
+
+// definition returns the location of the table, column or enum at the position
+func (x *Server) definition(params positionParams) *location {
+	doc := x.documents[params.TextDocument.URI]
+	if doc == nil {
+		return nil
+	}
+
+	object := doc.objectAt(params.Position)
+	if object == `` {
+		return nil
+	}
+
+	src, i, ok := doc.definition(doc.sources(x.documents), object)
+	if !ok {
+		return nil
+	}
+
+	l := src.lines[i]
+	return &location{URI: src.uri, Range: l.span(i, l.KeyStart, l.KeyStart+len(l.Key))}
+}
+
+// hover returns the sql of the column or enum at the position
+func (x *Server) hover(params positionParams) *hover {
+	doc := x.documents[params.TextDocument.URI]
+	if doc == nil {
+		return nil
+	}
+
+	object := doc.objectAt(params.Position)
+	if object == `` {
+		return nil
+	}
+
+	var value string
+	if col := doc.column(object); col != nil {
+		value = "```sql\n" + x.dialect.QuoteIdentifier(col.Table) + `.` + x.dialect.QuoteIdentifier(col.Name) + ` ` + x.columnSQL(col) + "\n```"
+	} else if enm := doc.model.Enums[object]; enm != nil {
+		var values []string
+		for _, v := range enm.Values {
+			values = append(values, x.dialect.QuoteLiteral(v))
+		}
+		value = "```sql\nCREATE TYPE " + x.dialect.QuoteIdentifier(enm.Name) + ` AS ENUM (` + strings.Join(values, `, `) + ")\n```"
+	} else {
+		return nil
+	}
+
+	if description := doc.model.Description(object); description != `` {
+		value += "\n\n" + description
+	}
+
+	return &hover{Contents: markupContent{Kind: `markdown`, Value: value}}
+}
+
+// sqlType returns the sql type of the column, a foreign key has the type and size of the column it references
+func (x *Server) sqlType(col *model.Column) string {
+	if col == nil {
+		return ``
+	}
+
+	size := col.Size
+	for ref := col.Ref; ref != nil; ref = ref.Ref {
+		size = ref.Size
+	}
+
+	return x.dialect.Type(col.Type(), size)
+}
+
+// columnSQL returns the sql type of the column with its constraints
+func (x *Server) columnSQL(col *model.Column) string {
+	q := x.sqlType(col)
+	if col.Primary != `` {
+		q += ` PRIMARY KEY`
+	}
+	if col.NotNull && col.Primary == `` {
+		q += ` NOT NULL`
+	}
+	if col.Unique != `` {
+		q += ` UNIQUE`
+	}
+	if col.Default != `` {
+		q += ` DEFAULT ` + col.Default
+	}
+	if col.Check != `` {
+		q += ` CHECK (` + col.Check + `)`
+	}
+	if col.Ref != nil {
+		q += ` REFERENCES ` + x.dialect.QuoteIdentifier(col.Ref.Table) + `(` + x.dialect.QuoteIdentifier(col.Ref.Name) + `)`
+	}
+
+	return q
+}
+
+// rename renames the table, column or enum at the position with its references,
+// the descriptions and seed rows of a column and the types of the columns of an enum
+func (x *Server) rename(params renameParams) (*workspaceEdit, error) {
+	doc := x.documents[params.TextDocument.URI]
+	if doc == nil {
+		return nil, nil
+	}
+
+	object := doc.objectAt(params.Position)
+	if object == `` {
+		return nil, nil
+	}
+
+	if strings.Contains(params.NewName, `.`) {
+		return nil, fmt.Errorf("%s is not a valid name, the table or schema of an object can't be changed by renaming it", params.NewName)
+	}
+
+	var kind string
+	switch {
+	case doc.column(object) != nil:
+		kind = `column`
+	case doc.model.Tables[object] != nil:
+		kind = `table`
+	case doc.model.Enums[object] != nil:
+		kind = `enum`
+	default:
+		return nil, fmt.Errorf("%s can't be renamed", object)
+	}
+
+	if err := model.ValidateName(kind, params.NewName); err != nil {
+		return nil, err
+	}
+
+	if uses := dependents(doc.model, object, kind); len(uses) > 0 {
+		return nil, fmt.Errorf("%s can't be renamed, it's used in %s which would have to be changed by hand", object, strings.Join(uses, `, `))
+	}
+
+	edit := &workspaceEdit{Changes: map[string][]textEdit{}}
+	for _, src := range doc.sources(x.documents) {
+		var edits []textEdit
+		for i, l := range src.lines {
+			if l.Key == `` {
+				continue
+			}
+
+			// the key of the object, a column description or a seed row
+			if l.Object() == object || (kind == `column` && doc.keyObject(l) == object) {
+				edits = append(edits, textEdit{
+					Range:   l.span(i, l.KeyStart, l.KeyStart+len(l.Key)),
+					NewText: params.NewName,
+				})
+				continue
+			}
+
+			if !l.isColumn() {
+				continue
+			}
+
+			token := typeTokenReg.FindString(l.Value)
+			resolved := doc.resolve(doc.schema(l), token)
+			if resolved == `` {
+				continue
+			}
+
+			// the index of the renamed part of the type from the end of the type
+			part := -1
+			switch {
+			case kind == `column` && resolved == object:
+				part = 0
+			case kind == `enum` && resolved == object:
+				part = 0
+			case kind == `table` && doc.column(resolved) != nil && doc.column(resolved).Table == object:
+				part = 1
+			}
+			if part < 0 {
+				continue
+			}
+
+			parts := strings.Split(token, `.`)
+			start := l.ValueStart + strings.Index(l.Text[l.ValueStart:], token)
+			for _, p := range parts[:len(parts)-1-part] {
+				start += len(p) + 1
+			}
+
+			edits = append(edits, textEdit{
+				Range:   l.span(i, start, start+len(parts[len(parts)-1-part])),
+				NewText: params.NewName,
+			})
+		}
+
+		if len(edits) > 0 {
+			edit.Changes[src.uri] = edits
+		}
+	}
+
+	return edit, nil
+}
+
+// dependents returns the view queries, check and using expressions and unique groups which
+// refer to the object by its name, a rename doesn't rewrite these expressions
+func dependents(mdl *model.Model, object, kind string) []string {
+	name := object
+	if i := strings.LastIndex(object, `.`); i >= 0 {
+		name = object[i+1:]
+	}
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+
+	// the columns of a check or using expression are the columns of its table
+	var owner string
+	if kind == `column` {
+		owner = strings.TrimSuffix(object, `.`+name)
+	}
+
+	var uses []string
+	for table, cols := range mdl.Tables {
+		if owner != `` && table != owner {
+			continue
+		}
+
+		for _, c := range cols {
+			if word.MatchString(c.Check) {
+				uses = append(uses, `the check of `+table+`.`+c.Name)
+			}
+			if word.MatchString(c.Using) {
+				uses = append(uses, `the using expression of `+table+`.`+c.Name)
+			}
+			if owner != `` && c.Name != name && c.Unique == name {
+				uses = append(uses, `the unique group of `+table+`.`+c.Name)
+			}
+		}
+	}
+
+	for view, v := range mdl.Views {
+		if word.MatchString(v.Query) {
+			uses = append(uses, `the view `+view)
+		}
+	}
+	sort.Strings(uses)
+
+	return uses
+}
@@ -0,0 +1,215 @@
+// Package lsp implements a language server for the configuration, it publishes
+// the errors of the model as diagnostics and offers the completion of types,
+// enums and references, the definition of references, the sql type of a column
+// on hover and the renaming of tables, columns and enums with their references.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/myceliums/gdb/dialect"
+)
+
+// Server is a language server which communicates over the given reader and writer
+// with JSON-RPC messages that are framed by a Content-Length header
+type Server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	dialect   dialect.Dialect
+	documents map[string]*document
+	shutdown  bool
+}
+
+// NewServer returns a language server which reads its requests from in and
+// writes its responses to out, the sql types are shown in the given dialect
+func NewServer(in io.Reader, out io.Writer, dia dialect.Dialect) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		dialect:   dia,
+		documents: map[string]*document{},
+	}
+}
+
+// Serve handles the requests until the client sends exit or closes the connection
+func (x *Server) Serve() error {
+	for {
+		body, err := x.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := x.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == `exit` {
+			return nil
+		}
+
+		if err := x.handle(req); err != nil {
+			return err
+		}
+	}
+}
+
+// read returns the body of the next message
+func (x *Server) read() ([]byte, error) {
+	header, err := textproto.NewReader(x.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get(`Content-Length`))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get(`Content-Length`))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(x.in, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// write writes the message with its Content-Length header
+func (x *Server) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(x.out, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+
+	_, err = x.out.Write(body)
+	return err
+}
+
+func (x *Server) reply(id *json.RawMessage, result interface{}) error {
+	return x.write(response{JSONRPC: `2.0`, ID: id, Result: result})
+}
+
+func (x *Server) replyError(id *json.RawMessage, code int, msg string) error {
+	return x.write(errorResponse{JSONRPC: `2.0`, ID: id, Error: responseError{Code: code, Message: msg}})
+}
+
+func (x *Server) notify(method string, params interface{}) error {
+	return x.write(notification{JSONRPC: `2.0`, Method: method, Params: params})
+}
+
+// handle dispatches the request, unknown notifications are ignored
+func (x *Server) handle(req request) error {
+	if x.shutdown && req.ID != nil {
+		return x.replyError(req.ID, codeInvalidRequest, `the server is shut down`)
+	}
+
+	var result interface{}
+	var err error
+
+	switch req.Method {
+	case `initialize`:
+		result = map[string]interface{}{
+			`capabilities`: map[string]interface{}{
+				`textDocumentSync`:   1,
+				`completionProvider`: map[string]interface{}{`triggerCharacters`: []string{`.`, ` `}},
+				`definitionProvider`: true,
+				`hoverProvider`:      true,
+				`renameProvider`:     true,
+			},
+			`serverInfo`: map[string]interface{}{`name`: `gdb`},
+		}
+	case `shutdown`:
+		x.shutdown = true
+	case `textDocument/didOpen`:
+		var params didOpenParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			return x.open(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case `textDocument/didChange`:
+		var params didChangeParams
+		if err = json.Unmarshal(req.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			return x.open(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case `textDocument/didClose`:
+		var params didCloseParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			delete(x.documents, params.TextDocument.URI)
+			return x.notify(`textDocument/publishDiagnostics`, publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+		}
+	case `textDocument/completion`:
+		var params positionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			result = x.completion(params)
+		}
+	case `textDocument/definition`:
+		var params positionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			if loc := x.definition(params); loc != nil {
+				result = loc
+			}
+		}
+	case `textDocument/hover`:
+		var params positionParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			if h := x.hover(params); h != nil {
+				result = h
+			}
+		}
+	case `textDocument/rename`:
+		var params renameParams
+		if err = json.Unmarshal(req.Params, &params); err == nil {
+			var edit *workspaceEdit
+			if edit, err = x.rename(params); err == nil && edit != nil {
+				result = edit
+			}
+		}
+	default:
+		if req.ID == nil {
+			return nil
+		}
+		return x.replyError(req.ID, codeMethodNotFound, `unknown method `+req.Method)
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+
+	if err != nil {
+		return x.replyError(req.ID, codeInvalidParams, err.Error())
+	}
+
+	return x.reply(req.ID, result)
+}
+
+// open stores the text of the document and publishes its diagnostics
+func (x *Server) open(uri, text string) error {
+	doc := newDocument(uri, text, x.documents[uri])
+	x.documents[uri] = doc
+
+	return x.notify(`textDocument/publishDiagnostics`, publishDiagnosticsParams{URI: uri, Diagnostics: doc.diagnostics()})
+}
+
+// lineRange returns the range of the text of the line without its indentation
+func lineRange(l line, i int) textRange {
+	start := len(l.Text) - len(strings.TrimLeft(l.Text, ` `))
+	return l.span(i, start, len(l.Text))
+}
@@ -0,0 +1,356 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/dialect"
+)
+
+const uri = `file:///tmp/db.yml`
+
+var config = `accounts:
+  id: serial primary
+  email: varchar(100) not null
+  status: account_status not null
+  description:
+    email: the login address
+  seed:
+  - id: 1
+    email: admin@example.com
+    status: active
+
+posts:
+  id: int primary
+  author: accounts.id not null
+
+account_status:
+- active
+- blocked
+`
+
+// message is a response or notification of the server
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// serve sends the requests to a server and returns the messages it wrote, the
+// requests without an id are sent as notification
+func serve(t *testing.T, requests ...map[string]interface{}) []message {
+	in := &bytes.Buffer{}
+	for _, req := range requests {
+		req[`jsonrpc`] = `2.0`
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(in, "Content-Length: %d\r\n\r\n%s", len(body), body) // nolint: errcheck
+	}
+
+	out := &bytes.Buffer{}
+	if err := NewServer(in, out, dialect.GetByDriver(`postgres`)).Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []message
+	rd := bufio.NewReader(out)
+	for {
+		header, err := textproto.NewReader(rd).ReadMIMEHeader()
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		length, _ := strconv.Atoi(header.Get(`Content-Length`))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(rd, body); err != nil {
+			t.Fatal(err)
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+func open(text string) map[string]interface{} {
+	return map[string]interface{}{
+		`method`: `textDocument/didOpen`,
+		`params`: map[string]interface{}{`textDocument`: map[string]interface{}{`uri`: uri, `version`: 1, `text`: text}},
+	}
+}
+
+func at(id int, method string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		`id`:     id,
+		`method`: method,
+		`params`: map[string]interface{}{
+			`textDocument`: map[string]interface{}{`uri`: uri},
+			`position`:     map[string]interface{}{`line`: line, `character`: character},
+		},
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	as := assert.New(t)
+
+	messages := serve(t,
+		map[string]interface{}{`id`: 1, `method`: `initialize`, `params`: map[string]interface{}{}},
+		map[string]interface{}{`method`: `initialized`, `params`: map[string]interface{}{}},
+		map[string]interface{}{`id`: 2, `method`: `unknown`},
+		map[string]interface{}{`id`: 3, `method`: `shutdown`},
+		map[string]interface{}{`method`: `exit`},
+		map[string]interface{}{`id`: 4, `method`: `shutdown`},
+	)
+
+	as.Eq(3, len(messages))
+	as.Eq(1, *messages[0].ID)
+	as.True(strings.Contains(string(messages[0].Result), `"renameProvider":true`))
+	as.Eq(codeMethodNotFound, messages[1].Error.Code)
+	as.Eq(`null`, string(messages[2].Result))
+}
+
+func TestDiagnostics(t *testing.T) {
+	as := assert.New(t)
+
+	invalid := `accounts:
+  id: serial primary
+
+posts:
+  id: int primary
+  author: users.id
+`
+
+	messages := serve(t, open(invalid), open(config), open(`accounts: [`))
+	as.Eq(3, len(messages))
+
+	var params publishDiagnosticsParams
+	as.NoError(json.Unmarshal(messages[0].Params, &params))
+	as.Eq(`textDocument/publishDiagnostics`, messages[0].Method)
+	as.Eq(1, len(params.Diagnostics))
+	as.Eq(5, params.Diagnostics[0].Range.Start.Line)
+	as.Eq(2, params.Diagnostics[0].Range.Start.Character)
+	as.True(strings.Contains(params.Diagnostics[0].Message, `users.id`))
+
+	as.NoError(json.Unmarshal(messages[1].Params, &params))
+	as.Eq(0, len(params.Diagnostics))
+
+	as.NoError(json.Unmarshal(messages[2].Params, &params))
+	as.Eq(1, len(params.Diagnostics))
+}
+
+func TestCompletion(t *testing.T) {
+	as := assert.New(t)
+
+	messages := serve(t, open(config+"\nlikes:\n  post: \n  account: accounts.id \n"),
+		at(1, `textDocument/completion`, 20, 8),
+		at(2, `textDocument/completion`, 21, 23),
+		at(3, `textDocument/completion`, 0, 2),
+	)
+
+	var items []completionItem
+	as.NoError(json.Unmarshal(messages[1].Result, &items))
+
+	labels := map[string]string{}
+	for _, item := range items {
+		labels[item.Label] = item.Detail
+	}
+	as.Eq(`VARCHAR`, labels[`varchar`])
+	as.Eq(`enum active, blocked`, labels[`account_status`])
+	as.Eq(`INT`, labels[`posts.id`])
+	as.Eq(`VARCHAR(100)`, labels[`accounts.email`])
+
+	as.NoError(json.Unmarshal(messages[2].Result, &items))
+	as.Eq(`primary`, items[0].Label)
+	as.Eq(completionKeyword, items[0].Kind)
+
+	as.Eq(`[]`, string(messages[3].Result))
+}
+
+func TestDefinition(t *testing.T) {
+	as := assert.New(t)
+
+	messages := serve(t, open(config),
+		at(1, `textDocument/definition`, 13, 20),
+		at(2, `textDocument/definition`, 3, 14),
+		at(3, `textDocument/definition`, 13, 12),
+	)
+
+	var loc location
+	as.NoError(json.Unmarshal(messages[1].Result, &loc))
+	as.Eq(uri, loc.URI)
+	as.Eq(textRange{Start: position{Line: 1, Character: 2}, End: position{Line: 1, Character: 4}}, loc.Range)
+
+	as.NoError(json.Unmarshal(messages[2].Result, &loc))
+	as.Eq(15, loc.Range.Start.Line)
+
+	as.NoError(json.Unmarshal(messages[3].Result, &loc))
+	as.Eq(0, loc.Range.Start.Line)
+
+	// a column named view refers to a type instead of being a query
+	messages = serve(t, open("accounts:\n  id: int primary\npages:\n  id: int primary\n  view: accounts.id\n"),
+		at(1, `textDocument/definition`, 4, 10),
+	)
+	loc = location{}
+	as.NoError(json.Unmarshal(messages[1].Result, &loc))
+	as.Eq(uri, loc.URI)
+	as.Eq(0, loc.Range.Start.Line)
+}
+
+func TestHover(t *testing.T) {
+	as := assert.New(t)
+
+	messages := serve(t, open(config),
+		at(1, `textDocument/hover`, 13, 4),
+		at(2, `textDocument/hover`, 2, 4),
+		at(3, `textDocument/hover`, 3, 14),
+		at(4, `textDocument/hover`, 0, 4),
+	)
+
+	var h hover
+	as.NoError(json.Unmarshal(messages[1].Result, &h))
+	as.Eq("```sql\n\"posts\".\"author\" INT NOT NULL REFERENCES \"accounts\"(\"id\")\n```", h.Contents.Value)
+
+	as.NoError(json.Unmarshal(messages[2].Result, &h))
+	as.Eq("```sql\n\"accounts\".\"email\" VARCHAR(100) NOT NULL\n```\n\nthe login address", h.Contents.Value)
+
+	as.NoError(json.Unmarshal(messages[3].Result, &h))
+	as.Eq("```sql\nCREATE TYPE \"account_status\" AS ENUM ('active', 'blocked')\n```", h.Contents.Value)
+
+	as.Eq(`null`, string(messages[4].Result))
+}
+
+func TestPositions(t *testing.T) {
+	as := assert.New(t)
+
+	// é is 2 bytes and 1 utf-16 code unit, 😀 is 4 bytes and 2 code units
+	l := scan("posts:\n  title: text default('é😀') # the accounts.id\n")[1]
+	as.Eq(23, l.offset(23))
+	as.Eq(25, l.offset(24))
+	as.Eq(29, l.offset(26))
+	as.Eq(len(l.Text), l.offset(100))
+	as.Eq(26, l.character(29))
+	as.Eq(l.character(len(l.Text)), l.character(100))
+	as.Eq(textRange{Start: position{Line: 1, Character: 35}, End: position{Line: 1, Character: 46}}, l.span(1, 38, 49))
+
+	// the character of a position past the text is at the end of the line
+	messages := serve(t, open(config), at(1, `textDocument/definition`, 13, 100))
+	as.Eq(`null`, string(messages[1].Result))
+}
+
+func TestRename(t *testing.T) {
+	as := assert.New(t)
+
+	rename := func(id, line, character int, name string) map[string]interface{} {
+		req := at(id, `textDocument/rename`, line, character)
+		req[`params`].(map[string]interface{})[`newName`] = name
+		return req
+	}
+
+	messages := serve(t, open(config),
+		rename(1, 2, 3, `mail`),
+		rename(2, 13, 12, `users`),
+		rename(3, 15, 2, `state`),
+		rename(4, 1, 2, `accounts.key`),
+		rename(5, 2, 3, `Mail`),
+	)
+
+	lines := func(msg message) map[int]string {
+		var edit workspaceEdit
+		as.NoError(json.Unmarshal(msg.Result, &edit))
+
+		x := map[int]string{}
+		for _, e := range edit.Changes[uri] {
+			as.Eq(e.Range.Start.Line, e.Range.End.Line)
+			text := scan(config)[e.Range.Start.Line].Text
+			x[e.Range.Start.Line] = text[:e.Range.Start.Character] + e.NewText + text[e.Range.End.Character:]
+		}
+		return x
+	}
+
+	as.Cmp(map[int]string{
+		2: `  mail: varchar(100) not null`,
+		5: `    mail: the login address`,
+		8: `    mail: admin@example.com`,
+	}, lines(messages[1]))
+
+	as.Cmp(map[int]string{
+		0:  `users:`,
+		13: `  author: users.id not null`,
+	}, lines(messages[2]))
+
+	as.Cmp(map[int]string{
+		3:  `  status: state not null`,
+		15: `state:`,
+	}, lines(messages[3]))
+
+	as.NotNil(messages[4].Error)
+	as.NotNil(messages[5].Error)
+
+	// expressions which refer to the object aren't rewritten, so the rename is refused
+	messages = serve(t, open("accounts:\n  id: serial primary\n  age: int check(age>0)\n  name: varchar\n  email: varchar unique(name)\n\nadults:\n  view: SELECT name FROM accounts\n"),
+		rename(1, 0, 2, `users`),
+		rename(2, 2, 3, `years`),
+		rename(3, 3, 3, `title`),
+		rename(4, 1, 3, `key`),
+	)
+	as.True(strings.Contains(messages[1].Error.Message, `the view adults`))
+	as.True(strings.Contains(messages[2].Error.Message, `the check of accounts.age`))
+	as.True(strings.Contains(messages[3].Error.Message, `the unique group of accounts.email`))
+	as.Nil(messages[4].Error)
+}
+
+func TestInclude(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	main := filepath.Join(dir, `main.yml`)
+	billing := filepath.Join(dir, `billing.yml`)
+	as.NoError(ioutil.WriteFile(billing, []byte("billing:\n  invoices:\n    id: serial primary\n"), 0644))
+
+	text := "include: billing.yml\n\npayments:\n  id: serial primary\n  invoice: billing.invoices.id not null\n"
+	as.NoError(ioutil.WriteFile(main, []byte(text), 0644))
+
+	request := func(req map[string]interface{}) map[string]interface{} {
+		req[`params`].(map[string]interface{})[`textDocument`] = map[string]interface{}{`uri`: pathToURI(main), `text`: text}
+		return req
+	}
+
+	rename := request(at(2, `textDocument/rename`, 4, 28))
+	rename[`params`].(map[string]interface{})[`newName`] = `number`
+
+	messages := serve(t, request(open(``)), request(at(1, `textDocument/definition`, 4, 22)), rename)
+	as.Eq(3, len(messages))
+
+	var params publishDiagnosticsParams
+	as.NoError(json.Unmarshal(messages[0].Params, &params))
+	as.Eq(0, len(params.Diagnostics))
+
+	var loc location
+	as.NoError(json.Unmarshal(messages[1].Result, &loc))
+	as.Eq(pathToURI(billing), loc.URI)
+	as.Eq(1, loc.Range.Start.Line)
+
+	var edit workspaceEdit
+	as.NoError(json.Unmarshal(messages[2].Result, &edit))
+	as.Cmp([]textEdit{{Range: textRange{Start: position{Line: 4, Character: 28}, End: position{Line: 4, Character: 30}}, NewText: `number`}}, edit.Changes[pathToURI(main)])
+	as.Cmp([]textEdit{{Range: textRange{Start: position{Line: 2, Character: 4}, End: position{Line: 2, Character: 6}}, NewText: `number`}}, edit.Changes[pathToURI(billing)])
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// descriptionKey is the key of the column descriptions in a table configuration
+const descriptionKey = `description`
+
+// newDescriptions returns the column descriptions of the description mapping of a table,
+// a column named description has a string value and is not a description mapping
+func newDescriptions(table string, m map[interface{}]interface{}) (map[string]string, error) {
+	descriptions, ok := m[descriptionKey].(map[interface{}]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	x := map[string]string{}
+	for k, v := range descriptions {
+		col, kok := k.(string)
+		text, vok := v.(string)
+		if !kok || !vok {
+			return nil, fmt.Errorf("table %s has an invalid description %v: %v, want column: text", table, k, v)
+		}
+
+		x[col] = strings.TrimSpace(text)
+	}
+
+	return x, nil
+}
+
+// comments returns the comments of the keys of the yaml document, which are the
+// comment lines directly above a key and the comment behind it. The keys are
+// joined with a dot like table.column, a comment followed by an empty line,
+// like the header of a file, isn't part of a key.
+func comments(in []byte) map[string]string {
+	x := map[string]string{}
+	var pending []string
+
+	for _, line := range KeyLines(in) {
+		trimmed := strings.TrimSpace(line.Text)
+		if !line.Scalar && strings.HasPrefix(trimmed, `#`) {
+			pending = append(pending, strings.TrimSpace(strings.TrimLeft(trimmed, `#`)))
+			continue
+		}
+
+		text := pending
+		pending = nil
+		if line.Key == `` {
+			continue
+		}
+
+		if line.Comment != `` {
+			text = append(text, strings.TrimSpace(strings.TrimLeft(line.Comment, `#`)))
+		}
+
+		if name := line.Object(); name != `` && len(text) > 0 {
+			x[name] = strings.Join(text, ` `)
+		}
+	}
+
+	return x
+}
+
+// Description returns the description of the given object, a schema, table, enum or
+// view, or of a column as table.column. The description is read from the description
+// mapping of the table or from the comments of the configuration.
+func (x Model) Description(object string) string {
+	return x.Descriptions[object]
+}
+
+// describe adds the comments as description of the objects without a description
+func (x *Model) describe(comments map[string]string) {
+	for object, text := range comments {
+		if _, ok := x.Descriptions[object]; !ok {
+			x.Descriptions[object] = text
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"sort"
+)
+
+const (
+	// enumKey is the key of the values of an enum defined as a map
+	enumKey = `enum`
+
+	// recreateKey allows an enum defined as a map to be recreated
+	recreateKey = `recreate`
+
+	// oldEnumSuffix is appended to the name of an enum while it is recreated
+	oldEnumSuffix = `_old`
+)
+
+// diffEnums adds the changes to create the new enums and to add the new values of
+// the existing enums in their declared order. Enums of which values are removed
+// or reordered are recreated when allowed, the names of the replaced types are
+// returned since these can only be dropped after the tables are upgraded.
+func diffEnums(plan *Plan, prev, curr Model) (replaced []string) {
+	recreate := recreatedEnums(prev, curr)
+
+	var names []string
+	for name := range curr.Enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		enum := curr.Enums[name]
+
+		oenum, ok := prev.Enums[name]
+		if !ok {
+			plan.add(Change{Kind: KindAddEnum, Object: enum.Name, Name: enum.Name, Values: enum.Values})
+			continue
+		}
+
+		delete(prev.Enums, name)
+
+		if recreate[name] {
+			replaced = append(replaced, recreateEnum(plan, prev, curr, enum))
+			continue
+		}
+
+		for i, value := range enum.Values {
+			if contains(oenum.Values, value) {
+				continue
+			}
+
+			before := nextExisting(enum.Values[i+1:], oenum.Values)
+			plan.add(Change{Kind: KindAddEnumValue, Object: name, Name: name, Value: value, Before: before})
+		}
+	}
+
+	return replaced
+}
+
+// recreateEnum replaces the enum by a new type with the same name and converts
+// the columns using the enum to the new type, the name of the old type is returned
+func recreateEnum(plan *Plan, prev, curr Model, enum *Enum) string {
+	schema, name := splitName(enum.Name)
+	old := name + oldEnumSuffix
+
+	plan.add(Change{Kind: KindRenameEnum, Object: enum.Name, Name: enum.Name, To: old})
+	plan.add(Change{Kind: KindAddEnum, Object: enum.Name, Name: enum.Name, Values: enum.Values})
+
+	for _, col := range enumColumns(prev, curr, enum.Name) {
+		if col.Default != `` {
+			plan.add(columnChange(KindDropDefault, col))
+		}
+
+		change := columnChange(KindAlterType, col)
+		change.Type, change.From = enum.Name, `text`
+		plan.add(change)
+
+		if ccol := curr.Tables[col.Table][col.Name]; ccol.Default != `` {
+			plan.add(expressionChange(KindSetDefault, col, ccol.Default))
+		}
+	}
+
+	return qualify(schema, old)
+}
+
+// enumColumns returns the sorted columns of the previous model that use the
+// given enum and still use it in the current model
+func enumColumns(prev, curr Model, enum string) []*Column {
+	var cols []*Column
+	for table, tcols := range prev.Tables {
+		for name, col := range tcols {
+			ccol, ok := curr.Tables[table][name]
+			if ok && isEnum(col, enum) && isEnum(ccol, enum) {
+				cols = append(cols, col)
+			}
+		}
+	}
+
+	sort.Slice(cols, func(i, j int) bool {
+		if cols[i].Table != cols[j].Table {
+			return cols[i].Table < cols[j].Table
+		}
+
+		return cols[i].Name < cols[j].Name
+	})
+
+	return cols
+}
+
+func isEnum(col *Column, enum string) bool {
+	e, ok := underlying(col.Datatype).(*Enum)
+	return ok && e.Name == enum
+}
+
+// recreatedEnums returns the enums which values are removed or reordered
+// and which are allowed to be recreated
+func recreatedEnums(prev, curr Model) map[string]bool {
+	recreate := map[string]bool{}
+	for name, enum := range curr.Enums {
+		oenum, ok := prev.Enums[name]
+		if !ok || !enum.Recreate {
+			continue
+		}
+
+		var retained, oretained []string
+		for _, value := range enum.Values {
+			if contains(oenum.Values, value) {
+				retained = append(retained, value)
+			}
+		}
+
+		for _, value := range oenum.Values {
+			if contains(enum.Values, value) {
+				oretained = append(oretained, value)
+			}
+		}
+
+		if len(oretained) != len(oenum.Values) || !equal(retained, oretained) {
+			recreate[name] = true
+		}
+	}
+
+	return recreate
+}
+
+// nextExisting returns the first of the given values that already exists
+func nextExisting(values, existing []string) string {
+	for _, value := range values {
+		if contains(existing, value) {
+			return value
+		}
+	}
+
+	return ``
+}
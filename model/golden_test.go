@@ -0,0 +1,300 @@
+package model_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/dialect"
+	"github.com/myceliums/gdb/gdbtest"
+	"github.com/myceliums/gdb/model"
+)
+
+// The golden files of the tests are in testdata, run go test ./model -update to rewrite them
+
+func readConfig(t *testing.T, path string) model.Model {
+	in, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return gdbtest.Model(t, string(in))
+}
+
+func TestInitialSQL(t *testing.T) {
+	mdl := readConfig(t, `testmodel.yml`)
+	gdbtest.InitialSQL(t, dialect.GetByDriver(`postgres`), mdl, `testdata/initial.sql`)
+}
+
+func TestCompareSQL(t *testing.T) {
+	as := assert.New(t)
+	prev, curr := readConfig(t, `testmodel.yml`), readConfig(t, `testnextmodel.yml`)
+	as.Eq(5, len(curr.Tables))
+
+	var colCount int
+	for t := range curr.Tables {
+		colCount += len(curr.Tables[t])
+	}
+	as.Eq(22, colCount)
+
+	gdbtest.UpgradeSQL(t, dialect.GetByDriver(`postgres`), prev, curr, `testdata/upgrade.sql`)
+}
+
+func TestSchemaSQL(t *testing.T) {
+	prev := readConfig(t, `testmodel.yml`)
+	curr := gdbtest.Model(t, `
+accounts:
+  id: serial primary
+
+billing:
+  invoices:
+    id: serial primary
+    account_id: accounts.id not null
+`)
+
+	dia := dialect.GetByDriver(`postgres`)
+	gdbtest.InitialSQL(t, dia, curr, `testdata/schema_initial.sql`)
+	gdbtest.UpgradeSQL(t, dia, prev, curr, `testdata/schema_upgrade.sql`)
+	gdbtest.UpgradeSQL(t, dia, gdbtest.Model(t, string(curr.Config())), prev, `testdata/schema_drop.sql`)
+
+	// the sequence of a serial column is owned by the column, so it doesn't keep its schema from being dropped
+	serial := gdbtest.Model(t, "billing:\n  invoices:\n    id: serial primary\n")
+	gdbtest.InitialSQL(t, dia, serial, `testdata/schema_serial_initial.sql`)
+	gdbtest.UpgradeSQL(t, dia, serial, gdbtest.Model(t, ``), `testdata/schema_serial_drop.sql`)
+}
+
+func TestViewSQL(t *testing.T) {
+	conf := `
+accounts:
+  id: serial primary
+  username: %s
+
+account_names:
+  view: SELECT id, username FROM accounts
+  id: int
+  username: varchar
+
+named_accounts:
+  view: SELECT id FROM account_names
+  materialized: true
+  id: int
+
+unrelated:
+  view: SELECT 1 AS one
+  one: int
+`
+	prev := gdbtest.Model(t, fmt.Sprintf(conf, `varchar not null`))
+	curr := gdbtest.Model(t, fmt.Sprintf(conf, `text not null`))
+
+	dia := dialect.GetByDriver(`postgres`)
+	gdbtest.InitialSQL(t, dia, prev, `testdata/view_initial.sql`)
+	gdbtest.UpgradeSQL(t, dia, prev, curr, `testdata/view_upgrade.sql`)
+}
+
+func TestSeedSQL(t *testing.T) {
+	prev := gdbtest.Model(t, `
+roles:
+  id: serial primary
+  name: varchar not null
+  seed:
+  - {id: 1, name: admin}
+  - {id: 2, name: user}
+  - {id: 3, name: guest}
+`)
+	curr := gdbtest.Model(t, `
+roles:
+  id: serial primary
+  name: varchar not null
+  seed:
+  - {id: 1, name: admin}
+  - {id: 2, name: member}
+  - {id: 4, name: owner}
+
+permissions:
+  id: serial primary
+  role_id: roles.id not null
+  seed:
+  - {id: 1, role_id: 4}
+`)
+
+	dia := dialect.GetByDriver(`postgres`)
+	gdbtest.InitialSQL(t, dia, prev, `testdata/seed_initial.sql`)
+	gdbtest.UpgradeSQL(t, dia, prev, curr, `testdata/seed_upgrade.sql`)
+}
+
+func TestCheckSQL(t *testing.T) {
+	prev := gdbtest.Model(t, `
+products:
+  id: int primary
+  price: int check(price>0)
+  stock: int check(stock>=0)
+  weight: int
+`)
+	curr := gdbtest.Model(t, `
+products:
+  id: int primary
+  price: int check(price>=0)
+  stock: int
+  weight: int check(weight>0)
+  size: int check(size<100)
+`)
+
+	gdbtest.UpgradeSQL(t, dialect.GetByDriver(`postgres`), prev, curr, `testdata/check.sql`)
+}
+
+func TestAddColumnSQL(t *testing.T) {
+	prev := gdbtest.Model(t, `
+accounts:
+  id: int primary
+`)
+	curr := gdbtest.Model(t, `
+accounts:
+  id: int primary
+  name: varchar not null
+  active: boolean not null default(true)
+  number: int not null auto increment
+  status: status not null check(status<>'banned')
+  parent_id: accounts.id not null
+
+status:
+- active
+- banned
+`)
+
+	gdbtest.UpgradeSQL(t, dialect.GetByDriver(`postgres`), prev, curr, `testdata/add_column.sql`)
+}
+
+func TestEnumSQL(t *testing.T) {
+	as := assert.New(t)
+	prev := gdbtest.Model(t, `
+accounts:
+  id: int primary
+  mood: mood not null default('happy')
+  level: level
+
+mood:
+- happy
+- sad
+
+level:
+- low
+- lowest
+- high
+`)
+	curr := gdbtest.Model(t, `
+accounts:
+  id: int primary
+  mood: mood not null default('happy')
+  level: level
+
+mood:
+- happy
+- content
+- sad
+- angry
+
+level:
+  recreate: true
+  enum:
+  - high
+  - low
+`)
+
+	dia := dialect.GetByDriver(`postgres`)
+	gdbtest.UpgradeSQL(t, dia, prev, curr, `testdata/enum.sql`)
+
+	stored := gdbtest.Model(t, string(prev.Config()))
+	as.Eq(``, model.UpgradeSQL(dia, stored, stored))
+}
+
+func TestConversionSQL(t *testing.T) {
+	as := assert.New(t)
+	prev := gdbtest.Model(t, `
+accounts:
+  id: int primary
+  age: varchar
+  score: varchar
+  status: varchar
+  level: int
+`)
+	curr := gdbtest.Model(t, `
+accounts:
+  id: int primary
+  age: int using(nullif(trim(age), '')::int)
+  score: int
+  status: status
+  level: bigint
+
+status:
+- active
+- banned
+`)
+
+	dia := dialect.GetByDriver(`postgres`)
+
+	err := model.CheckConversions(dia, prev, curr)
+	as.Error(err)
+	as.Eq(`column accounts.score cannot be converted from varchar to int without a using(...) expression`, err.Error())
+
+	gdbtest.UpgradeSQL(t, dia, prev, curr, `testdata/conversion.sql`)
+
+	as.NoError(model.CheckConversions(dia, readConfig(t, `testmodel.yml`), readConfig(t, `testnextmodel.yml`)))
+}
+
+func TestOnlineUpgradeSQL(t *testing.T) {
+	prev := gdbtest.Model(t, `
+accounts:
+  id: int primary
+  email: varchar
+  name: varchar
+
+roles:
+  id: int
+`)
+	curr := gdbtest.Model(t, `
+accounts:
+  id: int primary
+  email: varchar unique(email)
+  name: varchar not null check(name<>'')
+  role_id: roles.id
+
+roles:
+  id: int primary
+
+posts:
+  id: int primary
+  account_id: accounts.id not null
+
+grants:
+  id: int primary
+  role_id: roles.id not null
+`)
+
+	sq, steps := model.OnlineUpgradeSQL(dialect.GetByDriver(`postgres`), prev, curr)
+	gdbtest.Golden(t, `testdata/online.sql`, sq)
+
+	var all []string
+	for _, step := range steps {
+		all = append(all, strings.Join(step, ``))
+	}
+	gdbtest.Golden(t, `testdata/online_steps.sql`, strings.Join(all, "\n"))
+
+	// the foreign keys to roles follow the step which adds its primary key
+	as := assert.New(t)
+	step := func(substr string) int {
+		for i, s := range all {
+			if strings.Contains(s, substr) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	as.False(strings.Contains(sq, `REFERENCES "roles"`))
+	as.True(step(`"pk_roles" PRIMARY KEY USING INDEX`) >= 0)
+	as.True(step(`"fk_accounts_role_id" FOREIGN KEY`) > step(`"pk_roles" PRIMARY KEY USING INDEX`))
+	as.True(step(`"fk_grants_role_id" FOREIGN KEY`) > step(`"pk_roles" PRIMARY KEY USING INDEX`))
+	as.True(step(`VALIDATE CONSTRAINT "fk_accounts_role_id"`) > step(`"fk_accounts_role_id" FOREIGN KEY`))
+}
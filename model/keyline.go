@@ -0,0 +1,148 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// keyLineReg matches the indentation, the optionally quoted key and the value of a yaml mapping line
+// [1] indentation
+// [2] key with its quotes
+// [3] key
+// [4] value and comment
+var keyLineReg = regexp.MustCompile(`^( *)(['"]?([\w.\-]+)['"]?)\s*:(?:\s+(.*))?$`)
+
+// KeyLine is a line of a yaml configuration with the key it defines
+type KeyLine struct {
+	// Text is the line without its line ending
+	Text string
+	// Path contains the key of the line and the keys it's nested in, an item of a sequence is a -
+	Path []string
+	// Key is the unquoted key of the line, it's empty when the line has no key
+	Key string
+	// RawKey is the key as it's written, with its quotes
+	RawKey string
+	// KeyStart and ValueStart are the byte offsets of the key and the value in the line
+	KeyStart   int
+	ValueStart int
+	// Value is the value behind the key without the comment behind it
+	Value string
+	// Comment is the comment behind the value starting with its #
+	Comment string
+	// Scalar is true for the lines of a block scalar, like the lines of a multi-line view query
+	Scalar bool
+}
+
+// Object returns the key of the line and the keys it's nested in joined with a dot like
+// table.column, or an empty string when the line has no key or is nested in a sequence
+func (x KeyLine) Object() string {
+	if x.Key == `` {
+		return ``
+	}
+
+	for _, k := range x.Path {
+		if k == `-` {
+			return ``
+		}
+	}
+
+	return strings.Join(x.Path, `.`)
+}
+
+// KeyLines returns the lines of the yaml document with the keys they define. yaml.v2
+// doesn't keep the lines of the parsed nodes, so the document is scanned line by line
+// and the keys are nested by their indentation.
+func KeyLines(in []byte) []KeyLine {
+	type key struct {
+		indent int
+		name   string
+	}
+
+	var x []KeyLine
+	var stack []key
+	block := -1
+
+	for _, s := range strings.Split(string(in), "\n") {
+		s = strings.TrimRight(s, "\r")
+		l := KeyLine{Text: s}
+
+		trimmed := strings.TrimSpace(s)
+		indent := len(s) - len(strings.TrimLeft(s, ` `))
+
+		// lines of a block scalar like a view query belong to the key above them
+		if block >= 0 {
+			if trimmed == `` || indent > block {
+				l.Scalar = true
+				x = append(x, l)
+				continue
+			}
+			block = -1
+		}
+
+		if trimmed == `` || strings.HasPrefix(trimmed, `#`) || trimmed == `---` {
+			x = append(x, l)
+			continue
+		}
+
+		// the items of a sequence may have the indentation of the key they belong to
+		item := strings.HasPrefix(trimmed, `-`)
+		for len(stack) > 0 && (stack[len(stack)-1].indent > indent || stack[len(stack)-1].indent == indent && (!item || stack[len(stack)-1].name == `-`)) {
+			stack = stack[:len(stack)-1]
+		}
+
+		// the key of a sequence item like - id: 1 is nested in the item
+		rest := s
+		if item {
+			stack = append(stack, key{indent, `-`})
+			rest = strings.TrimLeft(strings.TrimPrefix(trimmed, `-`), ` `)
+			rest = strings.Repeat(` `, len(s)-len(rest)) + rest
+		}
+
+		match := keyLineReg.FindStringSubmatchIndex(rest)
+		if match == nil {
+			x = append(x, l)
+			continue
+		}
+
+		l.RawKey = rest[match[4]:match[5]]
+		l.Key = rest[match[6]:match[7]]
+		l.KeyStart = match[6]
+		l.ValueStart = len(rest)
+		if match[8] >= 0 {
+			l.ValueStart = match[8]
+			l.Value, l.Comment = splitComment(rest[match[8]:match[9]])
+		}
+
+		stack = append(stack, key{match[6], l.Key})
+		if strings.HasPrefix(l.Value, `|`) || strings.HasPrefix(l.Value, `>`) {
+			block = indent
+		}
+
+		for _, k := range stack {
+			l.Path = append(l.Path, k.name)
+		}
+		x = append(x, l)
+	}
+
+	return x
+}
+
+// splitComment splits a value from its trailing comment, a # only starts
+// a comment at the start of the value or after whitespace and not inside of a quoted value
+func splitComment(s string) (value, comment string) {
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case (r == '\'' || r == '"') && strings.TrimSpace(s[:i]) == ``:
+			quote = r
+		case r == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimSpace(s[:i]), s[i:]
+		}
+	}
+
+	return strings.TrimSpace(s), ``
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/myceliums/assert"
+)
+
+func TestKeyLines(t *testing.T) {
+	as := assert.New(t)
+	lines := KeyLines([]byte(`# accounts
+accounts:
+  'id': int primary # the identifier
+  seed:
+  - id: 1
+active:
+  view: |
+    SELECT id: 1
+  materialized: true
+`))
+
+	as.Eq(10, len(lines))
+	as.Eq(``, lines[0].Key)
+	as.Cmp([]string{`accounts`}, lines[1].Path)
+
+	as.Eq(`accounts.id`, lines[2].Object())
+	as.Eq(`'id'`, lines[2].RawKey)
+	as.Eq(3, lines[2].KeyStart)
+	as.Eq(`int primary`, lines[2].Value)
+	as.Eq(`# the identifier`, lines[2].Comment)
+	as.Eq(len(lines[1].Text), lines[1].ValueStart)
+
+	as.Eq(`id`, lines[4].Key)
+	as.Eq(``, lines[4].Object())
+	as.Cmp([]string{`accounts`, `seed`, `-`, `id`}, lines[4].Path)
+
+	as.True(lines[7].Scalar)
+	as.Eq(``, lines[7].Key)
+	as.Eq(`active.materialized`, lines[8].Object())
+}
@@ -0,0 +1,242 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// includeKey is the top level key used to include other configuration files
+const includeKey = `include`
+
+// NewFromFiles returns a new initialized model of the given configuration
+// files and directories. Directories are read for all .yml and .yaml files and
+// files can include others using the include key, the tables, enums, views and
+// schemas of all files are merged into a single canonical configuration.
+func NewFromFiles(paths ...string) (*Model, error) {
+	return loadFiles(New, paths)
+}
+
+// NewFromHistory returns the model of configuration files of an earlier version, like
+// a checkout of a git revision. Like the configuration stored by a migration, the
+// names aren't validated again and their legacy unquoted names are read in lowercase.
+func NewFromHistory(paths ...string) (*Model, error) {
+	return loadFiles(stored, paths)
+}
+
+// loadFiles loads and merges the files and returns the model of the merged configuration
+func loadFiles(newModel func(in []byte) (*Model, error), paths []string) (*Model, error) {
+	l := &loader{visited: map[string]bool{}, origins: map[string]string{}, merged: map[string]interface{}{}, comments: map[string]string{}}
+
+	for _, path := range paths {
+		if err := l.load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(l.docs) == 1 && !l.included {
+		return newModel(l.docs[0])
+	}
+
+	in, err := yaml.Marshal(l.merged)
+	if err != nil {
+		return nil, err
+	}
+
+	mdl, err := newModel(in)
+	if err != nil {
+		return nil, err
+	}
+
+	// the comments are lost when the files are merged
+	mdl.describe(l.comments)
+	return mdl, nil
+}
+
+// Files returns the configuration files of the given files and directories and
+// the files they include, in the order they're loaded. When a file can't be read
+// or parsed the files up to and including that file are returned with the error.
+func Files(paths ...string) ([]string, error) {
+	l := &loader{visited: map[string]bool{}, listing: true}
+
+	for _, path := range paths {
+		if err := l.load(path); err != nil {
+			return l.files, err
+		}
+	}
+
+	return l.files, nil
+}
+
+// loader reads and merges configuration files
+type loader struct {
+	visited  map[string]bool
+	origins  map[string]string
+	merged   map[string]interface{}
+	docs     [][]byte
+	comments map[string]string
+	included bool
+	// files are the loaded files, when listing is set the files are only listed and not merged
+	files   []string
+	listing bool
+}
+
+// load loads the given file or all configuration files in the given directory
+func (x *loader) load(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		x.files = append(x.files, path)
+		return err
+	}
+
+	if !info.IsDir() {
+		return x.loadFile(path)
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, f := range files {
+		if ext := filepath.Ext(f.Name()); !f.IsDir() && (ext == `.yml` || ext == `.yaml`) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := x.loadFile(filepath.Join(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (x *loader) loadFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if x.visited[abs] {
+		return nil
+	}
+	x.visited[abs] = true
+	x.files = append(x.files, path)
+
+	in, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(in, &doc); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	if !x.listing {
+		x.docs = append(x.docs, in)
+		for object, text := range comments(in) {
+			x.comments[object] = text
+		}
+	}
+
+	if include, ok := doc[includeKey]; ok {
+		x.included = true
+		delete(doc, includeKey)
+
+		includes, err := includePaths(include)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		for _, inc := range includes {
+			if !filepath.IsAbs(inc) {
+				inc = filepath.Join(filepath.Dir(path), inc)
+			}
+
+			if err := x.load(inc); err != nil {
+				return err
+			}
+		}
+	}
+
+	if x.listing {
+		return nil
+	}
+
+	return x.merge(path, doc)
+}
+
+// merge merges the given document in the merged configuration, schemas
+// defined in multiple files are merged, all other objects can only be defined once
+func (x *loader) merge(path string, doc map[string]interface{}) error {
+	for name, t := range doc {
+		m, ok := t.(map[interface{}]interface{})
+		if !ok || !isSchema(m) {
+			if err := x.claim(path, name); err != nil {
+				return err
+			}
+
+			x.merged[name] = t
+			continue
+		}
+
+		schema, ok := x.merged[name].(map[interface{}]interface{})
+		if !ok || !isSchema(schema) {
+			if err := x.claim(path, name); err != nil {
+				return err
+			}
+
+			schema = map[interface{}]interface{}{}
+			x.merged[name] = schema
+		}
+
+		for kk, mm := range m {
+			if err := x.claim(path, fmt.Sprintf("%s.%v", name, kk)); err != nil {
+				return err
+			}
+
+			schema[kk] = mm
+		}
+	}
+
+	return nil
+}
+
+// claim registers the given object as defined in the given file
+func (x *loader) claim(path, name string) error {
+	if origin, ok := x.origins[name]; ok && origin != path {
+		return fmt.Errorf("'%s' is defined in both %s and %s", name, origin, path)
+	}
+
+	x.origins[name] = path
+	return nil
+}
+
+func includePaths(include interface{}) ([]string, error) {
+	switch v := include.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		var paths []string
+		for _, p := range v {
+			s, ok := p.(string)
+			if !ok || strings.TrimSpace(s) == `` {
+				return nil, fmt.Errorf("include has an invalid path %v", p)
+			}
+			paths = append(paths, s)
+		}
+		return paths, nil
+	}
+
+	return nil, fmt.Errorf("include should be a path or a list of paths, but has %T", include)
+}
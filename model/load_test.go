@@ -0,0 +1,94 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myceliums/assert"
+)
+
+func TestNewFromFiles(t *testing.T) {
+	as := assert.New(t)
+	dir := writeFiles(t, map[string]string{
+		`main.yml`: `
+include:
+- ./billing/
+accounts:
+  id: serial primary
+`,
+		`billing/invoices.yml`: `
+billing:
+  invoices:
+    id: serial primary
+    account_id: accounts.id not null
+`,
+		`billing/currency.yaml`: `
+billing:
+  currency:
+  - eur
+  - usd
+`,
+		`billing/notes.txt`: `not a configuration`,
+	})
+
+	x, err := NewFromFiles(filepath.Join(dir, `main.yml`))
+	as.NoError(err)
+	as.Eq(2, len(x.Tables))
+	as.Eq(1, len(x.Enums))
+	as.Cmp([]string{`billing`}, x.Schemas)
+
+	stored, err := New(x.Config())
+	as.NoError(err)
+	as.Eq(2, len(stored.Tables))
+	as.Eq(1, len(stored.Enums))
+
+	x, err = NewFromFiles(filepath.Join(dir, `billing`), filepath.Join(dir, `main.yml`))
+	as.NoError(err)
+	as.Eq(2, len(x.Tables))
+
+	files, err := Files(filepath.Join(dir, `main.yml`), filepath.Join(dir, `billing`))
+	as.NoError(err)
+	as.Cmp([]string{filepath.Join(dir, `main.yml`), filepath.Join(dir, `billing`, `currency.yaml`), filepath.Join(dir, `billing`, `invoices.yml`)}, files)
+
+	// a missing file is listed with the error so it can be watched
+	files, err = Files(filepath.Join(dir, `missing.yml`))
+	as.Error(err)
+	as.Cmp([]string{filepath.Join(dir, `missing.yml`)}, files)
+}
+
+func TestNewFromFilesConflict(t *testing.T) {
+	as := assert.New(t)
+	dir := writeFiles(t, map[string]string{
+		`a.yml`: "accounts:\n  id: int\n",
+		`b.yml`: "accounts:\n  name: varchar\n",
+		`c.yml`: "billing:\n  accounts:\n    id: int\n",
+		`d.yml`: "billing:\n  accounts:\n    name: varchar\n",
+	})
+
+	_, err := NewFromFiles(filepath.Join(dir, `a.yml`), filepath.Join(dir, `b.yml`))
+	as.Error(err)
+
+	_, err = NewFromFiles(filepath.Join(dir, `c.yml`), filepath.Join(dir, `d.yml`))
+	as.Error(err)
+
+	_, err = New([]byte("include: a.yml\n"))
+	as.Error(err)
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
@@ -1,10 +1,13 @@
 package model
 
 import (
+	"context"
 	"database/sql"
-	"io"
-	"log"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/myceliums/gdb/dialect"
 )
@@ -13,120 +16,201 @@ import (
 // the given model and the last stored model in the database it will run a script
 // that will settle the differences safely between the stored model and the given one.
 func Migrate(dialect dialect.Dialect, db *sql.DB, mdl Model) error {
-	tx, err := db.Begin()
+	return MigrateContext(context.Background(), dialect, db, mdl, Options{Logger: StdLogger{}})
+}
+
+// MigrateContext runs the migration like Migrate, the context cancels the migration
+// and the options configure the logger, the hooks and the online mode.
+func MigrateContext(ctx context.Context, dialect dialect.Dialect, db *sql.DB, mdl Model, opts Options) (err error) {
+	start := time.Now()
+	version := 0
+	defer func() {
+		if err != nil {
+			opts.logger().Error(`migration failed`, `version`, version, `error`, err)
+		}
+		opts.complete(ctx, version, start, err)
+	}()
+
+	if opts.Online == nil {
+		version, _, err = migrateTx(ctx, dialect, db, mdl, opts)
+		return err
+	}
+
+	var steps []Statements
+	err = retry(ctx, dialect, opts, func() (err error) {
+		version, steps, err = migrateTx(ctx, dialect, db, mdl, opts)
+		return err
+	})
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback() // nolint: errcheck
+
+	return runSteps(ctx, dialect, db, opts, steps)
+}
+
+// migrateTx runs the migration in a transaction and returns the new version, which
+// is also returned when the migration fails once the stored version is known. When
+// the migration is online the steps to run after the transaction are returned.
+// New enum values can't be used in the transaction that adds them, so these are
+// committed in their own transaction before the other statements run.
+func migrateTx(ctx context.Context, dialect dialect.Dialect, db *sql.DB, mdl Model, opts Options) (version int, steps []Statements, err error) {
+	tx, err := begin(ctx, dialect, db, opts)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		tx.Rollback() // nolint: errcheck
+	}()
 
 	q := dialect.AddVersionTable()
-	if _, err := tx.Exec(q); err != nil {
-		return err
+	if _, err := tx.ExecContext(ctx, q); err != nil {
+		return 0, nil, err
 	}
 
 	q = dialect.CheckVersion()
-	var version int
 	var storedConfig []byte
-	if err := tx.QueryRow(q).Scan(&version, &storedConfig); err != nil && err != sql.ErrNoRows {
-		return err
-	}
-
-	q = dialect.InsertVersion()
-	if _, err := tx.Exec(q, version+1, mdl.Config()); err != nil {
-		return err
+	if err := tx.QueryRowContext(ctx, q).Scan(&version, &storedConfig); err != nil && err != sql.ErrNoRows {
+		return 0, nil, err
 	}
 
+	var stmts Statements
 	if version == 0 {
-		q = InitialSQL(dialect, mdl)
+		stmts = InitialStatements(dialect, mdl)
 	}
 
 	if version > 0 {
-		oldMdl, err := New(storedConfig)
+		oldMdl, err := stored(storedConfig)
 		if err != nil {
-			return err
+			return version + 1, nil, err
 		}
 
-		q = UpgradeSQL(dialect, *oldMdl, mdl)
-	}
-
-	log.Printf("Applying migration, version: %d:\n%s\n", version+1, q)
+		if err := CheckConversions(dialect, *oldMdl, mdl); err != nil {
+			return version + 1, nil, err
+		}
 
-	if _, err := tx.Exec(q); err != nil {
-		return err
+		if opts.Online != nil {
+			stmts, steps = OnlineUpgradeStatements(dialect, *oldMdl, mdl)
+		} else {
+			stmts = UpgradeStatements(dialect, *oldMdl, mdl)
+		}
 	}
 
-	return tx.Commit()
-}
+	values, others := stmts.split(KindAddEnumValue)
+	stmts = append(values, others...)
 
-// InitialSQL returns the sql to model the database after the given configuration.
-func InitialSQL(dialect dialect.Dialect, mdl Model) string {
-	builder := &strings.Builder{}
-	for _, enum := range mdl.Enums {
-		builder.WriteString(dialect.AddEnum(enum.Name, enum.Values))
-	}
+	opts.plan(ctx, version+1, stmts)
+	opts.logger().Info(`applying migration`, `version`, version+1, `statements`, len(stmts), `sql`, stmts.String())
 
-	for table, columns := range mdl.Tables {
-		addTable(builder, dialect, table, columns)
-	}
+	for i, stmt := range stmts {
+		if i > 0 && i == len(values) {
+			if err := tx.Commit(); err != nil {
+				return version + 1, nil, err
+			}
 
-	for _, cols := range mdl.Primaries {
-		var colNames []string
-		for _, col := range cols {
-			colNames = append(colNames, col.Name)
+			if tx, err = begin(ctx, dialect, db, opts); err != nil {
+				return version + 1, nil, err
+			}
 		}
-		builder.WriteString(dialect.AddPrimaryKey(cols[0].Table, colNames))
-	}
 
-	for id, cols := range mdl.Uniques {
-		var colNames []string
-		for _, col := range cols {
-			colNames = append(colNames, col.Name)
+		if err := opts.exec(ctx, tx, stmt); err != nil {
+			return version + 1, nil, &StatementError{Position: i + 1, Total: len(stmts), Statement: stmt, Err: err}
 		}
-		builder.WriteString(dialect.AddUnique(id, cols[0].Table, colNames))
 	}
 
-	for _, col := range mdl.Foreigns {
-		builder.WriteString(dialect.AddForeignKey(col.Table, col.Name, col.Ref.Table, col.Ref.Name))
+	q = dialect.InsertVersion()
+	if _, err := tx.ExecContext(ctx, q, version+1, mdl.Config()); err != nil {
+		return version + 1, nil, err
 	}
 
-	return builder.String()
+	return version + 1, steps, tx.Commit()
 }
 
-// UpgradeSQL returns the sql which resolves the differential safely between 2 models
-func UpgradeSQL(dialect dialect.Dialect, prev, curr Model) (q string) {
-	builder := &strings.Builder{}
-	for n, enum := range curr.Enums {
-		var osval, sval string
+// begin starts a transaction, in online mode with the timeouts of the options
+func begin(ctx context.Context, dialect dialect.Dialect, db *sql.DB, opts Options) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		oenum, ok := prev.Enums[n]
-		if !ok {
-			builder.WriteString(dialect.AddEnum(enum.Name, enum.Values)) // nolint: errcheck
-			goto ENUMLOOPEND
+	if online := opts.Online; online != nil {
+		if _, err := tx.ExecContext(ctx, dialect.SetTimeouts(online.LockTimeout, online.StatementTimeout, true)); err != nil {
+			tx.Rollback() // nolint: errcheck
+			return nil, err
 		}
+	}
+
+	return tx, nil
+}
 
-		osval = strings.Join(oenum.Values, ` `)
-		sval = strings.Join(enum.Values, ` `)
+// Stored returns the last version and model stored in the database, the version
+// is 0 and the model nil when the database hasn't been migrated yet
+func Stored(ctx context.Context, dialect dialect.Dialect, db *sql.DB) (version int, mdl *Model, err error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx, dialect.HasVersionTable()).Scan(&exists); err != nil || !exists {
+		return 0, nil, err
+	}
 
-		if osval == sval {
-			break
+	var storedConfig []byte
+	if err := db.QueryRowContext(ctx, dialect.CheckVersion()).Scan(&version, &storedConfig); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil, nil
 		}
+		return 0, nil, err
+	}
 
-		for _, val := range enum.Values {
-			if !strings.Contains(osval, val) {
-				builder.WriteString(dialect.AppendEnum(n, val)) // nolint: errcheck
-				osval = strings.Trim(osval, val)
-				osval = strings.TrimSuffix(osval, ` `)
+	mdl, err = stored(storedConfig)
+	return version, mdl, err
+}
+
+// CheckConversions returns an error for every column of which the type changes
+// between the models and the conversion needs an explicit using(...) expression
+func CheckConversions(dialect dialect.Dialect, prev, curr Model) error {
+	var msgs []string
+	for table, cols := range curr.Tables {
+		for name, col := range cols {
+			oldcol, ok := prev.Tables[table][name]
+			if !ok || col.Using != `` || (col.rawtype == oldcol.rawtype && col.Size == oldcol.Size) {
+				continue
 			}
-		}
 
-		delete(prev.Enums, n)
+			if _, ok := dialect.Cast(name, oldcol.Type(), col.Type()); !ok {
+				msgs = append(msgs, fmt.Sprintf("column %s.%s cannot be converted from %s to %s without a using(...) expression", table, name, oldcol.Type(), col.Type()))
+			}
+		}
+	}
 
-	ENUMLOOPEND:
+	if len(msgs) > 0 {
+		sort.Strings(msgs)
+		return errors.New(strings.Join(msgs, "\n"))
 	}
 
-	compareTables(builder, dialect, curr.Tables, prev.Tables)
+	return nil
+}
 
-	for k, cols := range curr.Primaries {
+// InitialSQL returns the sql to model the database after the given configuration.
+func InitialSQL(dialect dialect.Dialect, mdl Model) string {
+	return InitialStatements(dialect, mdl).String()
+}
+
+// InitialStatements returns the statements to model the database after the given configuration.
+func InitialStatements(dialect dialect.Dialect, mdl Model) Statements {
+	return Initial(mdl).Statements(dialect)
+}
+
+// UpgradeSQL returns the sql which resolves the differential safely between 2 models
+func UpgradeSQL(dialect dialect.Dialect, prev, curr Model) (q string) {
+	return UpgradeStatements(dialect, prev, curr).String()
+}
+
+// UpgradeStatements returns the statements which resolve the differential safely between 2 models
+func UpgradeStatements(dialect dialect.Dialect, prev, curr Model) Statements {
+	return Diff(prev, curr).Statements(dialect)
+}
+
+// compareKeys compares the primary keys, uniques and foreign keys of the models
+func compareKeys(plan *Plan, prev, curr Model) {
+	for _, k := range constraintNames(curr.Primaries) {
+		cols := curr.Primaries[k]
 		var names []string
 		var update bool
 
@@ -165,9 +249,9 @@ func UpgradeSQL(dialect dialect.Dialect, prev, curr Model) (q string) {
 
 	PRIMARYLOOPEND:
 		if !ok {
-			builder.WriteString(dialect.AddPrimaryKey(k, names)) // nolint: errcheck
+			plan.add(Change{Kind: KindAddPrimaryKey, Object: k, Table: k, Columns: names})
 		} else if update {
-			builder.WriteString(dialect.UpdatePrimaryKey(cols[0].Table, names)) // nolint: errcheck
+			plan.add(Change{Kind: KindAlterPrimaryKey, Object: cols[0].Table, Table: cols[0].Table, Columns: names})
 		}
 
 		if len(prev.Primaries[k]) < 1 {
@@ -175,7 +259,8 @@ func UpgradeSQL(dialect dialect.Dialect, prev, curr Model) (q string) {
 		}
 	}
 
-	for k, cols := range curr.Uniques {
+	for _, k := range constraintNames(curr.Uniques) {
+		cols := curr.Uniques[k]
 		var names []string
 		var update bool
 
@@ -214,9 +299,9 @@ func UpgradeSQL(dialect dialect.Dialect, prev, curr Model) (q string) {
 
 	UNIQUELOOPEND:
 		if !ok {
-			builder.WriteString(dialect.AddUnique(k, cols[0].Table, names)) // nolint: errcheck
+			plan.add(Change{Kind: KindAddUnique, Object: k, Name: k, Table: cols[0].Table, Columns: names})
 		} else if update {
-			builder.WriteString(dialect.UpdateUnique(k, cols[0].Table, names)) // nolint: errcheck
+			plan.add(Change{Kind: KindAlterUnique, Object: k, Name: k, Table: cols[0].Table, Columns: names})
 		}
 
 		if len(prev.Uniques[k]) < 1 {
@@ -225,57 +310,211 @@ func UpgradeSQL(dialect dialect.Dialect, prev, curr Model) (q string) {
 
 	}
 
-	for k, col := range curr.Foreigns {
+	for _, k := range foreignNames(curr.Foreigns) {
+		col := curr.Foreigns[k]
 		oldcol := prev.Foreigns[k]
 		if oldcol == nil {
-			builder.WriteString(dialect.AddForeignKey(col.Table, col.Name, col.Ref.Table, col.Ref.Name)) // nolint: errcheck
+			plan.add(foreignKey(KindAddForeignKey, col))
 			goto FOREIGNLOOPEND
 		}
 
 		if !(oldcol.Table == col.Table && oldcol.Name == col.Name) {
-			builder.WriteString(dialect.UpdateForeignKey(col.Table, col.Name, col.Ref.Table, col.Ref.Name)) // nolint: errcheck
+			plan.add(foreignKey(KindAlterForeignKey, col))
 		}
 
 	FOREIGNLOOPEND:
 		delete(prev.Foreigns, k)
 	}
 
-	for k, ocol := range prev.Foreigns {
-		if curr.Foreigns[k] == nil {
-			builder.WriteString(dialect.DropForeignKey(ocol.Table, ocol.Name)) // nolint: errcheck
+	// constraints of dropped tables and columns are dropped along with them
+	for _, k := range foreignNames(prev.Foreigns) {
+		ocol := prev.Foreigns[k]
+		if curr.Foreigns[k] == nil && !dropped(curr, ocol) && !dropped(curr, ocol.Ref) {
+			plan.add(Change{Kind: KindDropForeignKey, Object: columnObject(ocol.Table, ocol.Name), Table: ocol.Table, Column: ocol.Name})
 		}
 	}
 
-	for k, cols := range prev.Primaries {
-		if ncols, ok := curr.Primaries[k]; !ok || len(ncols) < 1 {
-			builder.WriteString(dialect.DropPrimaryKey(cols[0].Table)) // nolint: errcheck
+	for _, k := range constraintNames(prev.Primaries) {
+		cols := prev.Primaries[k]
+		if ncols, ok := curr.Primaries[k]; (!ok || len(ncols) < 1) && !dropped(curr, cols...) {
+			plan.add(Change{Kind: KindDropPrimaryKey, Object: cols[0].Table, Table: cols[0].Table})
 		}
 	}
 
-	for k, cols := range prev.Uniques {
-		if ncols, ok := curr.Uniques[k]; !ok || len(ncols) < 1 {
-			builder.WriteString(dialect.DropUnique(k, cols[0].Table)) // nolint: errcheck
+	for _, k := range constraintNames(prev.Uniques) {
+		cols := prev.Uniques[k]
+		if ncols, ok := curr.Uniques[k]; (!ok || len(ncols) < 1) && !dropped(curr, cols...) {
+			plan.add(Change{Kind: KindDropUnique, Object: k, Name: k, Table: cols[0].Table})
 		}
 	}
+}
 
-	for _, enum := range prev.Enums {
-		builder.WriteString(dialect.DropEnum(enum.Name)) // nolint: errcheck
+// dropped returns true when one of the columns doesn't exist in the model
+func dropped(mdl Model, cols ...*Column) bool {
+	for _, col := range cols {
+		if _, ok := mdl.Tables[col.Table][col.Name]; !ok {
+			return true
+		}
 	}
 
-	return builder.String()
+	return false
 }
 
-func compareTables(wr io.StringWriter, dialect dialect.Dialect, tables, old map[string]map[string]*Column) {
-	for tname, cols := range tables {
+// diffSeeds deletes the seed rows which are removed and upserts the new and
+// changed seed rows, rows of referenced tables are upserted first
+func diffSeeds(plan *Plan, prev, curr Model) {
+	var tables []string
+	for table := range curr.Seeds {
+		tables = append(tables, table)
+	}
+	for table := range prev.Seeds {
+		if _, ok := curr.Seeds[table]; !ok {
+			tables = append(tables, table)
+		}
+	}
+	tables = sortTables(curr, tables)
+
+	for i := len(tables) - 1; i >= 0; i-- {
+		table := tables[i]
+		if _, ok := curr.Tables[table]; !ok {
+			continue
+		}
+
+		keys, okeys := curr.PrimaryKey(table), prev.PrimaryKey(table)
+		rows := map[string]bool{}
+		for _, row := range curr.Seeds[table] {
+			rows[row.id(keys)] = true
+		}
+
+		for _, row := range prev.Seeds[table] {
+			if !equal(keys, okeys) || !rows[row.id(okeys)] {
+				plan.add(Change{Kind: KindDeleteRow, Object: table, Table: table, Keys: okeys, Row: row.Key(okeys)})
+			}
+		}
+	}
+
+	for _, table := range tables {
+		keys, okeys := curr.PrimaryKey(table), prev.PrimaryKey(table)
+		old := map[string]Row{}
+		for _, row := range prev.Seeds[table] {
+			old[row.id(okeys)] = row
+		}
+
+		upserted := false
+		for _, row := range curr.Seeds[table] {
+			if orow, ok := old[row.id(keys)]; ok && orow.equal(row) && equal(keys, okeys) {
+				continue
+			}
+
+			cols := row.Columns()
+			plan.add(Change{Kind: KindUpsertRow, Object: table, Table: table, Keys: keys, Columns: cols, Row: row.Key(cols)})
+			upserted = true
+		}
+
+		// the rows are inserted with their values, so the sequences continue after them
+		if upserted {
+			for _, name := range columnNames(curr.Tables[table]) {
+				if col := curr.Tables[table][name]; col.AutoIncement {
+					plan.add(columnChange(KindResetAutoIncrement, col))
+				}
+			}
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tableNames returns the sorted names of the tables, the maps of the model are
+// iterated in sorted order so the same models always result in the same sql
+func tableNames(tables map[string]map[string]*Column) []string {
+	var names []string
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// columnNames returns the sorted names of the columns
+func columnNames(cols map[string]*Column) []string {
+	var names []string
+	for name := range cols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// constraintNames returns the sorted names of the primary keys or unique constraints
+func constraintNames(constraints map[string][]*Column) []string {
+	var names []string
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// foreignNames returns the sorted table.column names of the foreign keys
+func foreignNames(foreigns map[string]*Column) []string {
+	var names []string
+	for name := range foreigns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// enumNames returns the sorted names of the enums
+func enumNames(enums map[string]*Enum) []string {
+	var names []string
+	for name := range enums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func compareTables(plan *Plan, tables, old map[string]map[string]*Column) {
+	for _, tname := range tableNames(tables) {
+		cols := tables[tname]
 		if old[tname] == nil {
-			addTable(wr, dialect, tname, tables[tname])
+			addTable(plan, tname, tables[tname])
 			goto TABLELOOPEND
 		}
 
-		for cname, col := range cols {
+		for _, cname := range columnNames(cols) {
+			col := cols[cname]
 			oldcol, ok := old[tname][cname]
 			if !ok {
-				wr.WriteString(dialect.AddColumn(col.Table, col.Name, col.Datatype.Type(), col.Size)) // nolint: errcheck
+				addColumn(plan, col, true)
 			}
 
 			if !ok || col.raw == oldcol.raw {
@@ -284,28 +523,39 @@ func compareTables(wr io.StringWriter, dialect dialect.Dialect, tables, old map[
 			}
 
 			if col.rawtype != oldcol.rawtype || col.Size != oldcol.Size {
-				wr.WriteString(dialect.UpdateColumn(tname, cname, col.Datatype.Type(), col.Size)) // nolint: errcheck
+				plan.add(Change{Kind: KindAlterType, Object: columnObject(tname, cname), Table: tname, Column: cname, Type: col.Type(), Size: col.Size, From: oldcol.Type(), Using: col.Using})
 			}
 
 			if col.AutoIncement && !oldcol.AutoIncement {
-				wr.WriteString(dialect.SetAutoIncrement(tname, cname)) // nolint: errcheck
+				plan.add(columnChange(KindSetAutoIncrement, col))
 			} else if !col.AutoIncement && oldcol.AutoIncement {
-				wr.WriteString(dialect.UnsetAutoIncrement(tname, cname)) // nolint: errcheck
+				plan.add(columnChange(KindDropAutoIncrement, col))
 			}
 
 			if col.NotNull != oldcol.NotNull {
 				if col.NotNull {
-					wr.WriteString(dialect.SetNotNull(col.Table, col.Name)) // nolint: errcheck
+					plan.add(columnChange(KindSetNotNull, col))
 				} else {
-					wr.WriteString(dialect.DeleteNotNull(col.Table, col.Name)) // nolint: errcheck
+					plan.add(columnChange(KindDropNotNull, col))
 				}
 			}
 
 			if col.Default != oldcol.Default {
 				if col.Default == `` {
-					wr.WriteString(dialect.DropDefault(col.Table, col.Name)) // nolint: errcheck
+					plan.add(columnChange(KindDropDefault, col))
 				} else {
-					wr.WriteString(dialect.SetDefault(col.Table, col.Name, col.Default)) // nolint: errcheck
+					plan.add(expressionChange(KindSetDefault, col, col.Default))
+				}
+			}
+
+			if col.Check != oldcol.Check {
+				switch {
+				case col.Check == ``:
+					plan.add(columnChange(KindDropCheck, col))
+				case oldcol.Check == ``:
+					plan.add(expressionChange(KindAddCheck, col, col.Check))
+				default:
+					plan.add(expressionChange(KindAlterCheck, col, col.Check))
 				}
 			}
 
@@ -319,44 +569,91 @@ func compareTables(wr io.StringWriter, dialect dialect.Dialect, tables, old map[
 		}
 	}
 
-	for table, cols := range old {
+	for _, table := range tableNames(old) {
+		cols := old[table]
 		if len(tables[table]) == 0 {
-			wr.WriteString(dialect.DropTable(table)) // nolint: errcheck
+			plan.add(Change{Kind: KindDropTable, Object: table, Table: table})
 			goto OLDTABLELOOPEND
 		}
 
-		for i, col := range cols {
-			if tables[table][i] == nil {
-				wr.WriteString(dialect.DropColumn(table, col.Name)) // nolint: errcheck
+		for _, name := range columnNames(cols) {
+			if col := cols[name]; tables[table][name] == nil {
+				plan.add(Change{Kind: KindDropColumn, Object: columnObject(table, col.Name), Table: table, Column: col.Name})
 			}
 		}
 	OLDTABLELOOPEND:
 	}
 }
 
-func addTable(wr io.StringWriter, dialect dialect.Dialect, table string, cols map[string]*Column) {
-	wr.WriteString(dialect.AddTable(table, false)) // nolint: errcheck
-	for _, col := range cols {
-		addColumn(wr, dialect, col)
+func addTable(plan *Plan, table string, cols map[string]*Column) {
+	plan.add(Change{Kind: KindAddTable, Object: table, Table: table})
+	for _, name := range columnNames(cols) {
+		addColumn(plan, cols[name], false)
 	}
 }
 
-func addColumn(wr io.StringWriter, dialect dialect.Dialect, col *Column) {
-	wr.WriteString(dialect.AddColumn(col.Table, col.Name, col.Datatype.Type(), col.Size)) // nolint: errcheck
+// addColumn adds the column with all of its properties, when the table may already
+// be populated a not null column is first backfilled with its default or the zero
+// value of its type before the not null constraint is set
+func addColumn(plan *Plan, col *Column, populated bool) {
+	change := columnChange(KindAddColumn, col)
+	change.Type, change.Size = col.Datatype.Type(), col.Size
+	plan.add(change)
 
 	if col.AutoIncement {
-		wr.WriteString(dialect.SetAutoIncrement(col.Table, col.Name)) // nolint: errcheck
+		plan.add(columnChange(KindSetAutoIncrement, col))
 	}
 
-	if col.NotNull {
-		wr.WriteString(dialect.SetNotNull(col.Table, col.Name)) // nolint: errcheck
+	if col.Default != `` {
+		plan.add(expressionChange(KindSetDefault, col, col.Default))
 	}
 
-	if col.Default != `` {
-		wr.WriteString(dialect.SetDefault(col.Table, col.Name, col.Default)) // nolint: errcheck
+	if col.NotNull {
+		if populated {
+			if change, ok := backfill(col); ok {
+				plan.add(change)
+			}
+		}
+
+		plan.add(columnChange(KindSetNotNull, col))
 	}
 
 	if col.Check != `` {
-		wr.WriteString(dialect.AddCheck(col.Table, col.Name, col.Check)) // nolint: errcheck
+		plan.add(expressionChange(KindAddCheck, col, col.Check))
+	}
+}
+
+// backfill returns the change which fills the existing rows of a new not null column,
+// foreign keys are never backfilled since no value can be guessed for them
+func backfill(col *Column) (Change, bool) {
+	change := columnChange(KindBackfill, col)
+	change.Type = col.Type()
+
+	switch {
+	case col.Default != `` || col.AutoIncement:
+		change.Expression = `DEFAULT`
+		return change, true
+	case col.Ref != nil:
+		return change, false
 	}
+
+	if enum, ok := col.Datatype.(*Enum); ok {
+		if len(enum.Values) < 1 {
+			return change, false
+		}
+
+		change.Value = enum.Values[0]
+	}
+
+	return change, true
+}
+
+func columnChange(kind Kind, col *Column) Change {
+	return Change{Kind: kind, Object: columnObject(col.Table, col.Name), Table: col.Table, Column: col.Name}
+}
+
+func expressionChange(kind Kind, col *Column, expression string) Change {
+	change := columnChange(kind, col)
+	change.Expression = expression
+	return change
 }
@@ -1,93 +1,217 @@
 package model
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/myceliums/assert"
 	"github.com/myceliums/gdb/dialect"
 )
 
-func TestInitialSQL(t *testing.T) {
-	x, as := initTest(t)
+func TestMigrate(t *testing.T) {
+	db, err := sql.Open(`postgres`, os.Getenv(`TEST_DB_CONNECTION_STRING`))
+	if err != nil {
+		t.Error(err)
+		t.Fail()
+	}
 
+	x, as := initTest(t)
 	dialect := dialect.GetByDriver(`postgres`)
 
-	initial := InitialSQL(dialect, *x)
-	as.Ne(``, initial)
-
-	initial = checkAndTrimString(as, initial, `CREATE TYPE bond_type AS ENUM ('companion', 'fiance', 'spouce', 'friend')`)
-	initial = checkAndTrimString(as, initial, `CREATE TABLE accounts();`)
-	initial = checkAndTrimString(as, initial, `ALTER TABLE accounts ADD COLUMN id INT;`)
-	//initial = checkAndTrimString(as, initial, `ALTER TABLE roles ALTER COLUMN id SET DATA TYPE SERIAL;`)
-	initial = checkAndTrimString(as, initial, `CREATE SEQUENCE seq_roles_id;`)
-	initial = checkAndTrimString(as, initial, `ALTER TABLE roles ALTER COLUMN id SET DEFAULT nextval('seq_roles_id'::regclass);`)
-	initial = checkAndTrimString(as, initial, `ALTER TABLE relationships ADD CONSTRAINT fk_relationships_account_id FOREIGN KEY (account_id) REFERENCES accounts(id);`)
-	initial = checkAndTrimString(as, initial, `ALTER TABLE relationships ADD COLUMN id INT;`)
-	initial = checkAndTrimString(as, initial, `ALTER TABLE relationships ADD COLUMN verified_at TIMESTAMP;`)
-	initial = checkAndTrimString(as, initial, `ALTER TABLE relationships ALTER COLUMN bond SET DEFAULT 'friend';`)
-	initial = checkAndTrimString(as, initial, `ALTER TABLE relationships ADD COLUMN bond bond_type;`)
-
-	if t.Failed() {
-		t.Log(initial)
-	}
+	as.NoError(Migrate(dialect, db, *x))
+
+	nextMdl := initModel(t, testNextModel)
+	as.NoError(Migrate(dialect, db, *nextMdl))
 }
 
-func TestCompareSQL(t *testing.T) {
-	x, as := initTest(t)
+func TestMigrateEnumValues(t *testing.T) {
+	as := assert.New(t)
+	prev := `
+role_kind:
+- user
+
+roles:
+  id: int primary
+  kind: role_kind not null default('user')
+  seed:
+  - {id: 1, kind: user}
+`
+	curr := `
+role_kind:
+- user
+- admin
+
+roles:
+  id: int primary
+  kind: role_kind not null default('admin')
+  seed:
+  - {id: 1, kind: user}
+  - {id: 2, kind: admin}
+`
+
+	conn := &fakeConn{version: 1, config: prev}
+	as.NoError(MigrateContext(context.Background(), dialect.GetByDriver(`postgres`), sql.OpenDB(conn), *initModel(t, []byte(curr)), Options{}))
+
+	as.Cmp([]string{
+		`BEGIN`,
+		`CREATE TABLE IF NOT EXISTS versions (id INT NOT NULL, config TEXT NOT NULL);`,
+		`SELECT id, config FROM versions ORDER BY id DESC;`,
+		`ALTER TYPE "role_kind" ADD VALUE IF NOT EXISTS 'admin';`,
+		`COMMIT`,
+		`BEGIN`,
+		`ALTER TABLE "roles" ALTER COLUMN "kind" SET DEFAULT 'admin';`,
+		`INSERT INTO "roles" ("id", "kind") VALUES ('2', 'admin') ON CONFLICT ("id") DO UPDATE SET "kind" = EXCLUDED."kind";`,
+		`INSERT INTO versions (id, config) VALUES($1, $2);`,
+		`COMMIT`,
+	}, conn.log)
+}
 
-	nextMdl := initModel(t, testNextModel)
-	as.Eq(5, len(nextMdl.Tables))
+func TestMigrateStoredNames(t *testing.T) {
+	as := assert.New(t)
+
+	// the stored configuration was migrated unquoted before names had to be lowercase,
+	// so the database folded its names to lowercase and nothing has to change
+	config := "Accounts:\n  ID: int primary\n  Role: Roles.ID unique(Login)\n  Kind: Kind default('Admin')\n  seed:\n  - {ID: 1, Kind: Admin}\nRoles:\n  ID: int primary\nKind:\n- Admin\n- User\n"
+	conn := &fakeConn{version: 1, config: config}
+	mdl := initModel(t, []byte("accounts:\n  id: int primary\n  role: roles.id unique(login)\n  kind: kind default('Admin')\n  seed:\n  - {id: 1, kind: Admin}\nroles:\n  id: int primary\nkind:\n- Admin\n- User\n"))
+
+	as.NoError(MigrateContext(context.Background(), dialect.GetByDriver(`postgres`), sql.OpenDB(conn), *mdl, Options{}))
+	as.Cmp([]string{
+		`BEGIN`,
+		`CREATE TABLE IF NOT EXISTS versions (id INT NOT NULL, config TEXT NOT NULL);`,
+		`SELECT id, config FROM versions ORDER BY id DESC;`,
+		`INSERT INTO versions (id, config) VALUES($1, $2);`,
+		`COMMIT`,
+	}, conn.log)
+
+	prev, err := stored([]byte(config))
+	as.NoError(err)
+	as.Eq(0, len(Diff(*prev, *mdl).Changes))
+
+	_, err = New([]byte(config))
+	as.Error(err)
+}
 
-	var colCount int
-	for t := range nextMdl.Tables {
-		colCount += len(nextMdl.Tables[t])
-	}
+// recordLogger records the messages and their arguments
+type recordLogger struct {
+	logs []string
+}
 
-	as.Eq(22, colCount)
+func (x *recordLogger) Info(msg string, args ...interface{}) {
+	x.logs = append(x.logs, format(msg, args))
+}
+func (x *recordLogger) Error(msg string, args ...interface{}) {
+	x.logs = append(x.logs, format(msg, args))
+}
 
-	dialect := dialect.GetByDriver(`postgres`)
+func TestMigrateContextFailure(t *testing.T) {
+	as := assert.New(t)
+
+	var versions []int
+	var failure error
+	logger := &recordLogger{}
+	opts := Options{Logger: logger, Hooks: Hooks{
+		Plan: func(ctx context.Context, version int, stmts Statements) {
+			versions = append(versions, version)
+			as.Eq(2, len(stmts))
+		},
+		Complete: func(ctx context.Context, version int, duration time.Duration, err error) {
+			versions = append(versions, version)
+			failure = err
+		},
+	}}
+
+	conn := &fakeConn{version: 4, config: "accounts:\n  id: int primary\n", fail: `"email"`}
+	mdl := initModel(t, []byte("accounts:\n  id: int primary\n  name: varchar\n  email: varchar\n"))
+	err := MigrateContext(context.Background(), dialect.GetByDriver(`postgres`), sql.OpenDB(conn), *mdl, opts)
+
+	var stmtErr *StatementError
+	as.True(errors.As(err, &stmtErr))
+	as.Eq(1, stmtErr.Position)
+	as.Eq(err, failure)
+	as.Cmp([]int{5, 5}, versions)
+	as.Eq(`ROLLBACK`, conn.log[len(conn.log)-1])
+	as.True(strings.HasPrefix(logger.logs[len(logger.logs)-1], "migration failed version=5\nstatement 1 of 2"), logger.logs)
+}
 
-	sq := UpgradeSQL(dialect, *x, *nextMdl)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE accounts ADD COLUMN bio TEXT;`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE relationships DROP COLUMN bond;`)
-	sq = checkAndTrimString(as, sq, `CREATE TABLE posts();`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ADD COLUMN id INT;`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ADD COLUMN type post_type;`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ALTER COLUMN type SET DEFAULT 'general';`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ADD COLUMN created_by INT;`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ADD CONSTRAINT fk_posts_created_by FOREIGN KEY (created_by) REFERENCES accounts(id);`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ALTER COLUMN created_by SET NOT NULL;`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ADD COLUMN created_at TIMESTAMP;`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ALTER COLUMN created_at SET DEFAULT NOW();`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ADD COLUMN context TEXT;`)
-	sq = checkAndTrimString(as, sq, `ALTER TABLE posts ALTER COLUMN context SET NOT NULL;`)
-	sq = checkAndTrimString(as, sq, `DROP TYPE bond_type;`)
-
-	if t.Failed() {
-		t.Log(sq)
-	}
+// fakeConn is a database connection which logs the statements and transactions,
+// the version query returns the stored version and configuration when the version
+// isn't 0 and a statement containing fail returns an error
+type fakeConn struct {
+	version int
+	config  string
+	fail    string
+	log     []string
 }
 
-func TestMigrate(t *testing.T) {
-	db, err := sql.Open(`postgres`, os.Getenv(`TEST_DB_CONNECTION_STRING`))
-	if err != nil {
-		t.Error(err)
-		t.Fail()
+func (x *fakeConn) Connect(context.Context) (driver.Conn, error) { return x, nil }
+func (x *fakeConn) Driver() driver.Driver                        { return nil }
+func (x *fakeConn) Prepare(q string) (driver.Stmt, error)        { return fakeStmt{x, q}, nil }
+func (x *fakeConn) Close() error                                 { return nil }
+
+func (x *fakeConn) Begin() (driver.Tx, error) {
+	x.log = append(x.log, `BEGIN`)
+	return x, nil
+}
+
+func (x *fakeConn) Commit() error {
+	x.log = append(x.log, `COMMIT`)
+	return nil
+}
+
+func (x *fakeConn) Rollback() error {
+	x.log = append(x.log, `ROLLBACK`)
+	return nil
+}
+
+type fakeStmt struct {
+	conn *fakeConn
+	q    string
+}
+
+func (x fakeStmt) Close() error  { return nil }
+func (x fakeStmt) NumInput() int { return -1 }
+
+func (x fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	x.conn.log = append(x.conn.log, strings.TrimSpace(x.q))
+	if x.conn.fail != `` && strings.Contains(x.q, x.conn.fail) {
+		return nil, errors.New(`failed`)
 	}
 
-	x, as := initTest(t)
-	dialect := dialect.GetByDriver(`postgres`)
+	return driver.RowsAffected(0), nil
+}
 
-	as.NoError(Migrate(dialect, db, *x))
+func (x fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	x.conn.log = append(x.conn.log, strings.TrimSpace(x.q))
 
-	nextMdl := initModel(t, testNextModel)
-	as.NoError(Migrate(dialect, db, *nextMdl))
+	var rows [][]driver.Value
+	if x.conn.version > 0 {
+		rows = append(rows, []driver.Value{int64(x.conn.version), []byte(x.conn.config)})
+	}
+
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
 }
 
-func checkAndTrimString(as assert.Assert, str, check string) string {
-	as.True(strings.Contains(str, check), "expected '", check, "' but not found")
-	return strings.Trim(str, check)
+func (x *fakeRows) Columns() []string { return []string{`id`, `config`} }
+func (x *fakeRows) Close() error      { return nil }
+
+func (x *fakeRows) Next(dest []driver.Value) error {
+	if len(x.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, x.rows[0])
+	x.rows = x.rows[1:]
+	return nil
 }
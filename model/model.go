@@ -3,7 +3,9 @@ package model
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -41,10 +43,21 @@ var (
 	// [2] id_name
 	uniqueReg = regexp.MustCompile(`unique(\((\w+)\))?`)
 
+	// usingReg matches the start of a using(expression) of which the
+	// expression is read until its closing parenthesis by getArgument
+	usingReg = regexp.MustCompile(`using\(`)
+
 	// autoIncrementReg
 	autoIncrementReg = regexp.MustCompile(`^serial|auto\ ?increment`)
+
+	// nameReg matches the names that can be used as identifier, these are
+	// lowercase since quoted identifiers are case sensitive in the database
+	nameReg = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
 )
 
+// maxNameLength is the maximum length of an identifier or enum value
+const maxNameLength = 63
+
 // DataType is a model data structure
 type DataType interface {
 	Type() string
@@ -60,50 +73,261 @@ func newConfig(in []byte) (*Config, error) {
 	x := new(Config)
 	x.Tables = map[string]map[string]string{}
 	x.Enums = map[string][]string{}
+	x.Views = map[string]ViewConfig{}
+	x.Seeds = map[string][]map[string]interface{}{}
+	x.Recreate = map[string]bool{}
+	x.Descriptions = map[string]string{}
 	x.raw = in
 
+	if _, ok := cfile[includeKey]; ok {
+		return nil, fmt.Errorf("'%s' can only be used when loading configuration files", includeKey)
+	}
+
 	for k, t := range cfile {
-		switch m := t.(type) {
-		case []interface{}:
-			var vals []string
-			for _, mm := range m {
-				if sm, ok := mm.(string); ok {
-					vals = append(vals, sm)
-				}
+		if err := x.add(``, k, t); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(x.Schemas)
+
+	return x, nil
+}
+
+// add adds the configuration of t under the given name, when the name is
+// given inside of a schema it will be qualified with the name of the schema
+func (x *Config) add(schema, name string, t interface{}) error {
+	qname := qualify(schema, name)
+
+	switch m := t.(type) {
+	case []interface{}:
+		var vals []string
+		for _, mm := range m {
+			if sm, ok := mm.(string); ok {
+				vals = append(vals, sm)
+			}
+		}
+		x.Enums[qname] = vals
+	case map[interface{}]interface{}:
+		if values, ok := m[enumKey].([]interface{}); ok {
+			if err := x.add(schema, name, values); err != nil {
+				return err
+			}
+
+			x.Recreate[qname], _ = m[recreateKey].(bool)
+			return nil
+		}
+
+		if isSchema(m) {
+			if schema != `` {
+				return fmt.Errorf("'%s' is defined as a schema inside of schema '%s', schemas cannot be nested", name, schema)
 			}
-			x.Enums[k] = vals
-		case map[interface{}]interface{}:
-			vals := map[string]string{}
+
+			x.Schemas = append(x.Schemas, name)
 			for kk, mm := range m {
-				sk, kok := kk.(string)
-				smm, vok := mm.(string)
-				if kok && vok {
-					vals[sk] = smm
+				sk, ok := kk.(string)
+				if !ok {
+					return fmt.Errorf("schema '%s' has a non string key %v", name, kk)
+				}
+
+				if err := x.add(name, sk, mm); err != nil {
+					return err
 				}
 			}
-			x.Tables[k] = vals
+
+			return nil
+		}
+
+		vals := map[string]string{}
+		for kk, mm := range m {
+			sk, kok := kk.(string)
+			smm, vok := mm.(string)
+			if kok && vok {
+				vals[sk] = smm
+			}
+		}
+
+		// a table can have a column named view, its value is a type instead of a query
+		if query, ok := vals[viewKey]; ok && IsQuery(query) {
+			delete(vals, viewKey)
+			materialized, _ := m[materializedKey].(bool)
+			x.Views[qname] = ViewConfig{Query: query, Materialized: materialized, Columns: vals}
+			return nil
+		}
+
+		if seed, ok := m[seedKey].([]interface{}); ok {
+			rows, err := newRows(qname, seed)
+			if err != nil {
+				return err
+			}
+			x.Seeds[qname] = rows
+		}
+
+		descriptions, err := newDescriptions(qname, m)
+		if err != nil {
+			return err
+		}
+
+		for col, text := range descriptions {
+			if _, ok := vals[col]; !ok {
+				return fmt.Errorf("table %s has a description of unknown column %s", qname, col)
+			}
+			x.Descriptions[qualify(qname, col)] = text
+		}
+
+		x.Tables[qname] = vals
+	default:
+		return fmt.Errorf("'%s' has an unknown configuration. want either map[string][]string, map[string]map[string]string or a schema containing these, but has %T", qname, t)
+	}
+
+	return nil
+}
+
+// isSchema checks if the given configuration is a schema, which is a map
+// that only contains tables and enums instead of columns
+func isSchema(m map[interface{}]interface{}) bool {
+	if len(m) < 1 {
+		return false
+	}
+
+	for _, v := range m {
+		switch v.(type) {
+		case []interface{}, map[interface{}]interface{}:
 		default:
-			return nil, fmt.Errorf("'%s' has an unknown configuration. want either map[string][]string or map[string]map[string]string, but has %T", k, t)
+			return false
 		}
 	}
 
-	return x, nil
+	return true
 }
 
+// qualify returns the name prefixed with the schema when a schema is given
+func qualify(schema, name string) string {
+	if schema == `` {
+		return name
+	}
+
+	return schema + `.` + name
+}
+
+// splitName splits a schema qualified name in its schema and its name,
+// the schema is empty for objects in the default schema
+func splitName(qname string) (schema, name string) {
+	i := strings.Index(qname, `.`)
+	if i < 0 {
+		return ``, qname
+	}
+
+	return qname[:i], qname[i+1:]
+}
+
+// Config is the parsed yaml configuration, tables, enums and views inside of
+// a schema are stored under their schema qualified name (schema.name).
+// Recreate contains the enums that may be recreated to remove or reorder values.
 type Config struct {
-	Tables map[string]map[string]string `yaml:"tables,flow"`
-	Enums  map[string][]string          `yaml:"enums,flow"`
-	raw    []byte
+	Tables   map[string]map[string]string        `yaml:"tables,flow"`
+	Enums    map[string][]string                 `yaml:"enums,flow"`
+	Recreate map[string]bool                     `yaml:"recreate,flow"`
+	Views    map[string]ViewConfig               `yaml:"views,flow"`
+	Seeds    map[string][]map[string]interface{} `yaml:"seeds,flow"`
+	Schemas  []string                            `yaml:"schemas,flow"`
+	// Descriptions contains the descriptions of the columns as table.column
+	Descriptions map[string]string `yaml:"descriptions,flow"`
+	raw          []byte
 }
 
 // New returns a new initialized model
 func New(in []byte) (*Model, error) {
+	return newModel(in, true)
+}
+
+// stored returns the model of a configuration stored by a previous migration. Its
+// names were valid when it was migrated and aren't validated again, so a stricter
+// name rule doesn't make an existing database impossible to migrate.
+func stored(in []byte) (*Model, error) {
+	return newModel(in, false)
+}
+
+// lowerNames lowercases the names of a stored configuration. Names were written unquoted
+// before they had to be lowercase, so the database folded them to lowercase and the
+// lowercase names are the names of the existing objects. Enum values and expressions are kept.
+func lowerNames(conf *Config) {
+	lower := strings.ToLower
+	// the type of a column can be a table.column or enum reference
+	lowerType := func(def string) string {
+		t := typeReg.FindString(def)
+		return lower(t) + def[len(t):]
+	}
+
+	tables := map[string]map[string]string{}
+	for table, cols := range conf.Tables {
+		tables[lower(table)] = map[string]string{}
+		for col, def := range cols {
+			def = lowerType(def)
+			if match := uniqueReg.FindStringSubmatchIndex(def); match != nil && match[4] >= 0 {
+				def = def[:match[4]] + lower(def[match[4]:match[5]]) + def[match[5]:]
+			}
+			tables[lower(table)][lower(col)] = def
+		}
+	}
+	conf.Tables = tables
+
+	enums := map[string][]string{}
+	for enum, values := range conf.Enums {
+		enums[lower(enum)] = values
+	}
+	conf.Enums = enums
+
+	recreate := map[string]bool{}
+	for enum, ok := range conf.Recreate {
+		recreate[lower(enum)] = ok
+	}
+	conf.Recreate = recreate
+
+	views := map[string]ViewConfig{}
+	for view, vc := range conf.Views {
+		cols := map[string]string{}
+		for col, def := range vc.Columns {
+			cols[lower(col)] = lowerType(def)
+		}
+		vc.Columns = cols
+		views[lower(view)] = vc
+	}
+	conf.Views = views
+
+	seeds := map[string][]map[string]interface{}{}
+	for table, rows := range conf.Seeds {
+		for _, row := range rows {
+			lrow := map[string]interface{}{}
+			for col, v := range row {
+				lrow[lower(col)] = v
+			}
+			seeds[lower(table)] = append(seeds[lower(table)], lrow)
+		}
+	}
+	conf.Seeds = seeds
+
+	for i, schema := range conf.Schemas {
+		conf.Schemas[i] = lower(schema)
+	}
+	sort.Strings(conf.Schemas)
+
+	descriptions := map[string]string{}
+	for object, text := range conf.Descriptions {
+		descriptions[lower(object)] = text
+	}
+	conf.Descriptions = descriptions
+}
+
+func newModel(in []byte, validate bool) (*Model, error) {
 	var x Model
 	x.Tables = map[string]map[string]*Column{}
 	x.Primaries = map[string][]*Column{}
 	x.Uniques = map[string][]*Column{}
 	x.Foreigns = map[string]*Column{}
 	x.Enums = map[string]*Enum{}
+	x.Views = map[string]*View{}
+	x.Seeds = map[string][]Row{}
 	x.aliases = primitiveTypesAliases()
 
 	conf, err := newConfig(in)
@@ -111,32 +335,62 @@ func New(in []byte) (*Model, error) {
 		return nil, err
 	}
 
+	if validate {
+		if err := validateConfig(conf); err != nil {
+			return nil, err
+		}
+	} else {
+		lowerNames(conf)
+	}
+
 	x.conf = conf
+	x.Schemas = conf.Schemas
+
+	x.Descriptions = map[string]string{}
+	for object, text := range conf.Descriptions {
+		x.Descriptions[object] = text
+	}
+	x.describe(comments(in))
 
 	x, err = appendTablesAndColums(x, conf.Tables)
 	if err != nil {
 		return nil, err
 	}
 
-	x = appendEnums(x, conf.Enums)
+	x = appendEnums(x, conf.Enums, conf.Recreate)
 
 	x, err = getDataTypes(x)
 	if err != nil {
 		return nil, err
 	}
 
+	x, err = appendViews(x, conf.Views)
+	if err != nil {
+		return nil, err
+	}
+
+	x, err = appendSeeds(x, conf.Seeds)
+	if err != nil {
+		return nil, err
+	}
+
 	return &x, nil
 }
 
 // Model contains the database structure
 type Model struct {
+	Schemas   []string
 	Tables    map[string]map[string]*Column
 	Enums     map[string]*Enum
 	Uniques   map[string][]*Column
 	Primaries map[string][]*Column
 	Foreigns  map[string]*Column
-	aliases   map[string]DataType
-	conf      *Config
+	Views     map[string]*View
+	Seeds     map[string][]Row
+	// Descriptions contains the descriptions of the objects, see Description
+	Descriptions map[string]string
+	aliases      map[string]DataType
+	conf         *Config
 }
 
 // Config returns the raw config
@@ -157,6 +411,7 @@ type Column struct {
 	Primary      string
 	Unique       string
 	AutoIncement bool
+	Using        string
 	rawtype      string
 	raw          string
 }
@@ -170,6 +425,8 @@ func (x *Column) Type() string {
 type Enum struct {
 	Name   string
 	Values []string
+	// Recreate allows the enum to be recreated to remove or reorder its values
+	Recreate bool
 }
 
 // Type is an implementation of Datatype
@@ -206,6 +463,30 @@ func getFirstSubmatch(reg *regexp.Regexp, context string) string {
 	return matches[1]
 }
 
+// getArgument returns the text between the parentheses that start at the end of
+// the match of the given expression, nested parentheses are part of the argument
+func getArgument(reg *regexp.Regexp, context string) string {
+	loc := reg.FindStringIndex(context)
+	if loc == nil {
+		return ``
+	}
+
+	depth := 1
+	for i := loc[1]; i < len(context); i++ {
+		switch context[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return context[loc[1]:i]
+			}
+		}
+	}
+
+	return ``
+}
+
 func getSecondSubmatchOrColumn(reg *regexp.Regexp, columnName, context string) string {
 	matches := reg.FindStringSubmatch(context)
 	length := len(matches)
@@ -220,12 +501,27 @@ func getSecondSubmatchOrColumn(reg *regexp.Regexp, columnName, context string) s
 }
 
 func appendTablesAndColums(m Model, tables map[string]map[string]string) (Model, error) {
-	for table, columns := range tables {
+	var tnames []string
+	for table := range tables {
+		tnames = append(tnames, table)
+	}
+	sort.Strings(tnames)
+
+	// the columns are added in sorted order, which is the order of the columns of a primary key or unique
+	for _, table := range tnames {
+		columns := tables[table]
 		if _, ok := m.Tables[table]; !ok {
 			m.Tables[table] = map[string]*Column{}
 		}
 
-		for name, content := range columns {
+		var cnames []string
+		for name := range columns {
+			cnames = append(cnames, name)
+		}
+		sort.Strings(cnames)
+
+		for _, name := range cnames {
+			content := columns[name]
 			tname := table
 
 			col := new(Column)
@@ -248,6 +544,10 @@ func appendTablesAndColums(m Model, tables map[string]map[string]string) (Model,
 			}
 
 			unique := getSecondSubmatchOrColumn(uniqueReg, name, content)
+			if err := ValidateName(`unique`, unique); unique != `` && err != nil {
+				return m, err
+			}
+
 			if unique != `` {
 				if m.Uniques[unique] == nil {
 					m.Uniques[unique] = []*Column{}
@@ -258,6 +558,7 @@ func appendTablesAndColums(m Model, tables map[string]map[string]string) (Model,
 
 			col.Default = getFirstSubmatch(defaultReg, content)
 			col.Check = getFirstSubmatch(checkReg, content)
+			col.Using = getArgument(usingReg, content)
 			col.NotNull = notnullReg.MatchString(content)
 			col.AutoIncement = autoIncrementReg.MatchString(content)
 
@@ -269,7 +570,7 @@ func appendTablesAndColums(m Model, tables map[string]map[string]string) (Model,
 	return m, nil
 }
 
-func appendEnums(m Model, enums map[string][]string) Model {
+func appendEnums(m Model, enums map[string][]string, recreate map[string]bool) Model {
 	if enums == nil {
 		m.Enums = map[string]*Enum{}
 	}
@@ -278,6 +579,7 @@ func appendEnums(m Model, enums map[string][]string) Model {
 		enum := new(Enum)
 		enum.Name = name
 		enum.Values = values
+		enum.Recreate = recreate[name]
 
 		m.Enums[name] = enum
 		m.aliases[name] = enum
@@ -289,11 +591,12 @@ func appendEnums(m Model, enums map[string][]string) Model {
 func getDataTypes(m Model) (Model, error) {
 	for table, cols := range m.Tables {
 		for _, col := range cols {
-			if m.aliases[col.rawtype] == nil {
+			datatype := m.lookup(table, col.rawtype)
+			if datatype == nil {
 				return m, fmt.Errorf("unrecognized datatype in table %s column %s type: %s", table, col.Name, col.rawtype)
 			}
 
-			col.Datatype = m.aliases[col.rawtype]
+			col.Datatype = datatype
 			if ref, ok := col.Datatype.(*Column); ok {
 				col.Ref = ref
 				m.Foreigns[col.Table+`.`+col.Name] = col
@@ -304,6 +607,93 @@ func getDataTypes(m Model) (Model, error) {
 	return m, nil
 }
 
+// lookup returns the datatype of the given alias, aliases are resolved in the
+// schema of the given table first so tables inside a schema can reference
+// each other without qualifying the schema
+func (x Model) lookup(table, alias string) DataType {
+	if schema, _ := splitName(table); schema != `` {
+		if datatype, ok := x.aliases[qualify(schema, alias)]; ok {
+			return datatype
+		}
+	}
+
+	return x.aliases[alias]
+}
+
+// validateConfig checks that all names in the configuration are valid identifiers
+func validateConfig(conf *Config) error {
+	for _, schema := range conf.Schemas {
+		if err := ValidateName(`schema`, schema); err != nil {
+			return err
+		}
+	}
+
+	for table, cols := range conf.Tables {
+		if err := validateQualifiedName(`table`, table); err != nil {
+			return err
+		}
+
+		for col := range cols {
+			if err := ValidateName(`column`, table+`.`+col); err != nil {
+				return err
+			}
+		}
+	}
+
+	for view, conf := range conf.Views {
+		if err := validateQualifiedName(`view`, view); err != nil {
+			return err
+		}
+
+		for col := range conf.Columns {
+			if err := ValidateName(`column`, view+`.`+col); err != nil {
+				return err
+			}
+		}
+	}
+
+	for enum, values := range conf.Enums {
+		if err := validateQualifiedName(`enum`, enum); err != nil {
+			return err
+		}
+
+		for _, value := range values {
+			if value == `` || len(value) > maxNameLength {
+				return fmt.Errorf("enum %s has an invalid value '%s', values must be between 1 and %d bytes", enum, value, maxNameLength)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateQualifiedName validates both the schema and the name of a schema qualified name
+func validateQualifiedName(kind, qname string) error {
+	schema, name := splitName(qname)
+	if schema != `` {
+		if err := ValidateName(`schema`, schema); err != nil {
+			return err
+		}
+	}
+
+	return ValidateName(kind, name)
+}
+
+// ValidateName checks if the last part of the given name is a valid identifier,
+// kind is the kind of object the name is used for in the error
+func ValidateName(kind, name string) error {
+	ident := name
+	if i := strings.LastIndex(name, `.`); i >= 0 {
+		ident = name[i+1:]
+	}
+
+	if !nameReg.MatchString(ident) || len(ident) > maxNameLength {
+		return fmt.Errorf("%s '%s' is not a valid name, names must be lowercase, start with a letter or underscore, contain only letters, digits and underscores and be at most %d characters", kind, name, maxNameLength)
+	}
+
+	return nil
+}
+
 type primitiveType string
 
 // Type is an implementation of the Datatype
@@ -311,6 +701,17 @@ func (x primitiveType) Type() string {
 	return string(x)
 }
 
+// PrimitiveTypes returns the sorted names of the primitive types a column can have
+func PrimitiveTypes() []string {
+	var types []string
+	for alias := range primitiveTypesAliases() {
+		types = append(types, alias)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
 func primitiveTypesAliases() map[string]DataType {
 	m := map[string]DataType{}
 	varchar := primitiveType(`varchar`)
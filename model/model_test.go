@@ -68,3 +68,175 @@ func initModel(t *testing.T, in []byte) *Model {
 
 	return x
 }
+
+func TestNewSchemas(t *testing.T) {
+	as := assert.New(t)
+	x := initModel(t, []byte(`
+accounts:
+  id: serial primary
+
+billing:
+  invoices:
+    id: serial primary
+    account_id: accounts.id not null
+    currency: currency default('eur')
+  invoice_lines:
+    id: serial primary
+    invoice_id: invoices.id not null
+  currency:
+  - eur
+  - usd
+`))
+
+	as.Cmp([]string{`billing`}, x.Schemas)
+	as.Eq(3, len(x.Tables))
+	as.Eq(1, len(x.Enums))
+
+	as.Eq(`billing.currency`, x.Tables[`billing.invoices`][`currency`].Type())
+	as.Eq(`accounts`, x.Tables[`billing.invoices`][`account_id`].Ref.Table)
+	as.Eq(`billing.invoices`, x.Tables[`billing.invoice_lines`][`invoice_id`].Ref.Table)
+	as.Eq(2, len(x.Foreigns))
+
+	_, err := New([]byte(`
+billing:
+  nested:
+    invoices:
+      id: serial primary
+`))
+	as.Error(err)
+}
+
+func TestNewViews(t *testing.T) {
+	as := assert.New(t)
+	x := initModel(t, []byte(`
+accounts:
+  id: serial primary
+  username: varchar not null
+
+account_names:
+  view: SELECT id, username FROM accounts;
+  id: accounts.id not null
+  username: varchar
+
+named_accounts:
+  view: SELECT id FROM account_names WHERE username IS NOT NULL
+  materialized: true
+  id: int
+`))
+
+	as.Eq(1, len(x.Tables))
+	as.Eq(2, len(x.Views))
+	as.Eq(0, len(x.Foreigns))
+
+	view := x.Views[`account_names`]
+	as.Eq(`SELECT id, username FROM accounts`, view.Query)
+	as.Eq(`int`, view.Columns[`id`].Type())
+	as.True(view.Columns[`id`].NotNull)
+	as.Cmp([]string{`accounts`}, view.Depends)
+
+	view = x.Views[`named_accounts`]
+	as.True(view.Materialized)
+	as.Cmp([]string{`account_names`}, view.Depends)
+
+	// a column named view or materialized has a type instead of a query
+	pages, err := New([]byte("pages:\n  id: int primary\n  view: int\n  materialized: bool\n"))
+	as.NoError(err)
+	as.Eq(3, len(pages.Tables[`pages`]))
+	as.Eq(0, len(pages.Views))
+
+	for _, query := range []string{`select 1 AS id`, `WITH x AS (SELECT 1 AS id) SELECT id FROM x`, `VALUES (1)`, `(SELECT 1 AS id)`} {
+		_, err := New([]byte("ids:\n  view: " + query + "\n  id: int\n"))
+		as.NoError(err, query)
+	}
+}
+
+func TestNewInvalidNames(t *testing.T) {
+	as := assert.New(t)
+
+	for _, conf := range []string{
+		"Accounts:\n  id: int\n",
+		"accounts:\n  user-name: varchar\n",
+		"accounts:\n  id: int unique(Some_ID)\n",
+		"Billing:\n  accounts:\n    id: int\n",
+		"mood:\n- ''\n",
+	} {
+		_, err := New([]byte(conf))
+		as.Error(err, conf)
+	}
+
+	x, err := New([]byte("order:\n  user: varchar\nmood:\n- it's fine\n"))
+	as.NoError(err)
+	as.Eq(1, len(x.Tables[`order`]))
+}
+
+func TestNewSeeds(t *testing.T) {
+	as := assert.New(t)
+	x := initModel(t, []byte(`
+roles:
+  id: int primary
+  name: varchar(10) unique not null
+  kind: role_kind not null default('user')
+  description: text
+  seed:
+  - {id: 1, name: admin, kind: admin}
+  - {id: 2, name: user, description: null}
+
+role_kind:
+- admin
+- user
+`))
+
+	as.Eq(2, len(x.Seeds[`roles`]))
+	as.Eq(4, len(x.Tables[`roles`]))
+	as.Eq(`admin`, *x.Seeds[`roles`][0][`kind`])
+	as.Nil(x.Seeds[`roles`][1][`description`])
+
+	for _, conf := range []string{
+		"roles:\n  id: int primary\n  seed:\n  - {id: one}\n",
+		"roles:\n  id: int primary\n  seed:\n  - {id: 1, name: x}\n",
+		"roles:\n  id: int primary\n  seed:\n  - {id: 1}\n  - {id: 1}\n",
+		"roles:\n  id: int\n  seed:\n  - {id: 1}\n",
+		"roles:\n  id: int primary\n  name: varchar not null\n  seed:\n  - {id: 1}\n",
+		"roles:\n  id: int primary\n  name: varchar(2)\n  seed:\n  - {id: 1, name: abc}\n",
+		"roles:\n  id: int primary\n  kind: kind\n  seed:\n  - {id: 1, kind: c}\nkind:\n- a\n- b\n",
+		"roles:\n  id: int primary\n  at: timestamp\n  seed:\n  - {id: 1, at: yesterday}\n",
+		"roles:\n  id: int primary\n  at: time\n  seed:\n  - {id: 1, at: '12:00:00'}\n",
+	} {
+		_, err := New([]byte(conf))
+		as.Error(err, conf)
+	}
+}
+
+func TestNewDescriptions(t *testing.T) {
+	as := assert.New(t)
+	x := initModel(t, []byte(`# This file contains the accounts
+
+# the users that can log in
+accounts:
+  id: int primary # the identifier
+  # the login of the account,
+  # unique over all accounts
+  email: varchar(100) not null
+  status: account_status
+  description:
+    status: whether the account can log in
+  seed:
+  - id: 1
+    # not a description
+    email: admin@example.com
+
+account_status:
+- active
+- blocked
+`))
+
+	as.Eq(`the users that can log in`, x.Description(`accounts`))
+	as.Eq(`the identifier`, x.Description(`accounts.id`))
+	as.Eq(`the login of the account, unique over all accounts`, x.Description(`accounts.email`))
+	as.Eq(`whether the account can log in`, x.Description(`accounts.status`))
+	as.Eq(``, x.Description(`accounts.seed.email`))
+	as.Eq(4, len(x.Descriptions))
+
+	_, err := New([]byte("accounts:\n  id: int primary\n  description:\n    name: unknown\n"))
+	as.Error(err)
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/myceliums/gdb/dialect"
+)
+
+// OnlineOptions configures an online migration
+type OnlineOptions struct {
+	// LockTimeout is the maximum time a statement waits for a lock
+	LockTimeout time.Duration
+	// StatementTimeout is the maximum duration of a statement, zero disables it
+	StatementTimeout time.Duration
+	// Retries is the number of times a statement is retried after a lock timeout
+	Retries int
+	// RetryDelay is the time waited before a statement is retried
+	RetryDelay time.Duration
+}
+
+// MigrateOnline runs the migration like Migrate but avoids long exclusive locks on
+// existing tables. Foreign keys and checks are added without validating the existing
+// rows, not null constraints are first added as check, and primary keys and uniques
+// are added after their index is built concurrently. The validation and the index
+// builds run after the migration transaction is committed, foreign keys which reference
+// such a primary key or unique are added after it. Each step is retried when it runs
+// into the lock timeout.
+func MigrateOnline(dialect dialect.Dialect, db *sql.DB, mdl Model, opts OnlineOptions) error {
+	return MigrateContext(context.Background(), dialect, db, mdl, Options{Logger: StdLogger{}, Online: &opts})
+}
+
+// OnlineUpgradeSQL returns the sql which resolves the differential between 2 models
+// in a transaction without long locks on existing tables, and the steps which
+// have to run one by one outside of the transaction afterwards
+func OnlineUpgradeSQL(dia dialect.Dialect, prev, curr Model) (q string, steps [][]string) {
+	stmts, ssteps := OnlineUpgradeStatements(dia, prev, curr)
+	for _, step := range ssteps {
+		var qs []string
+		for _, stmt := range step {
+			qs = append(qs, stmt.SQL)
+		}
+		steps = append(steps, qs)
+	}
+
+	return stmts.String(), steps
+}
+
+// OnlineUpgradeStatements returns the statements of OnlineUpgradeSQL
+func OnlineUpgradeStatements(dia dialect.Dialect, prev, curr Model) (Statements, []Statements) {
+	online := &onlineDialect{Dialect: dia, existing: map[string]bool{}, deferred: map[string]bool{}}
+	for table := range prev.Tables {
+		if _, ok := curr.Tables[table]; ok {
+			online.existing[table] = true
+		}
+	}
+
+	stmts := UpgradeStatements(online, prev, curr)
+
+	return stmts, online.steps
+}
+
+// runSteps runs the steps outside of a transaction on a single connection
+func runSteps(ctx context.Context, dialect dialect.Dialect, db *sql.DB, opts Options, steps []Statements) error {
+	if len(steps) < 1 {
+		return nil
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint: errcheck
+
+	if _, err := conn.ExecContext(ctx, dialect.SetTimeouts(opts.Online.LockTimeout, opts.Online.StatementTimeout, false)); err != nil {
+		return err
+	}
+
+	for i, step := range steps {
+		opts.logger().Info(`applying online migration step`, `step`, i+1, `steps`, len(steps), `sql`, step.String())
+
+		err := retry(ctx, dialect, opts, func() error {
+			for _, stmt := range step {
+				if err := opts.exec(ctx, conn, stmt); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			var remaining Statements
+			for _, step := range steps[i:] {
+				remaining = append(remaining, step...)
+			}
+
+			return fmt.Errorf("online migration step %d of %d failed: %v, the remaining statements have to be applied manually:\n%s", i+1, len(steps), err, remaining)
+		}
+	}
+
+	return nil
+}
+
+// retry runs fn until it succeeds, fails with another error than a lock
+// timeout, the number of retries is exceeded or the context is done
+func retry(ctx context.Context, dialect dialect.Dialect, opts Options, fn func() error) error {
+	for i := 0; ; i++ {
+		err := fn()
+		if err == nil || !dialect.IsLockTimeout(err) || i >= opts.Online.Retries {
+			return err
+		}
+
+		opts.logger().Info(`lock timeout, retrying`, `retry`, i+1, `retries`, opts.Online.Retries, `error`, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.Online.RetryDelay):
+		}
+	}
+}
+
+// onlineDialect wraps a dialect to defer the statements that take long
+// exclusive locks on existing tables to steps outside of the transaction
+type onlineDialect struct {
+	dialect.Dialect
+	existing map[string]bool
+	// deferred contains the table.column of the primary key and unique columns which are added by a step
+	deferred map[string]bool
+	steps    []Statements
+}
+
+// later adds a step of the given statements of the kind on the object
+func (x *onlineDialect) later(kind Kind, object string, qs ...string) {
+	step := &Statements{}
+	for _, q := range qs {
+		step.add(kind, object, kind.Destructive(), q)
+	}
+	x.steps = append(x.steps, *step)
+}
+
+func (x *onlineDialect) AddForeignKey(table, column, referenceTable, referenceColumn string) string {
+	// a foreign key needs the key it references, so it follows the step which adds that key
+	if x.deferred[columnObject(referenceTable, referenceColumn)] {
+		if !x.existing[table] {
+			x.later(KindAddForeignKey, columnObject(table, column), x.Dialect.AddForeignKey(table, column, referenceTable, referenceColumn))
+			return ``
+		}
+
+		x.later(KindAddForeignKey, columnObject(table, column), x.Dialect.AddForeignKeyNotValid(table, column, referenceTable, referenceColumn))
+		x.later(KindValidateForeignKey, columnObject(table, column), x.Dialect.ValidateForeignKey(table, column))
+		return ``
+	}
+
+	if !x.existing[table] {
+		return x.Dialect.AddForeignKey(table, column, referenceTable, referenceColumn)
+	}
+
+	x.later(KindValidateForeignKey, columnObject(table, column), x.Dialect.ValidateForeignKey(table, column))
+	return x.Dialect.AddForeignKeyNotValid(table, column, referenceTable, referenceColumn)
+}
+
+func (x *onlineDialect) AddCheck(table, column, check string) string {
+	if !x.existing[table] {
+		return x.Dialect.AddCheck(table, column, check)
+	}
+
+	x.later(KindValidateCheck, columnObject(table, column), x.Dialect.ValidateCheck(table, column))
+	return x.Dialect.AddCheckNotValid(table, column, check)
+}
+
+func (x *onlineDialect) SetNotNull(table, column string) string {
+	if !x.existing[table] {
+		return x.Dialect.SetNotNull(table, column)
+	}
+
+	x.later(KindValidateCheck, columnObject(table, column), x.Dialect.ValidateNotNullCheck(table, column))
+	x.later(KindSetNotNull, columnObject(table, column), x.Dialect.SetNotNull(table, column))
+	x.later(KindDropCheck, columnObject(table, column), x.Dialect.DropNotNullCheck(table, column))
+	return x.Dialect.AddNotNullCheck(table, column)
+}
+
+func (x *onlineDialect) AddPrimaryKey(table string, columns []string) string {
+	if !x.existing[table] {
+		return x.Dialect.AddPrimaryKey(table, columns)
+	}
+
+	x.deferKey(table, columns)
+	x.later(KindAddIndex, table, x.Dialect.DropPrimaryKeyIndex(table), x.Dialect.AddPrimaryKeyIndex(table, columns))
+	x.later(KindAddPrimaryKey, table, x.Dialect.AddPrimaryKeyUsingIndex(table))
+	return ``
+}
+
+func (x *onlineDialect) AddUnique(id, table string, columns []string) string {
+	if !x.existing[table] {
+		return x.Dialect.AddUnique(id, table, columns)
+	}
+
+	x.deferKey(table, columns)
+	x.later(KindAddIndex, id, x.Dialect.DropUniqueIndex(id, table), x.Dialect.AddUniqueIndex(id, table, columns))
+	x.later(KindAddUnique, id, x.Dialect.AddUniqueUsingIndex(id, table))
+	return ``
+}
+
+// deferKey marks the columns of a primary key or unique which is added by a step
+func (x *onlineDialect) deferKey(table string, columns []string) {
+	for _, column := range columns {
+		x.deferred[columnObject(table, column)] = true
+	}
+}
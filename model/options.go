@@ -0,0 +1,128 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Logger logs the progress of a migration, the arguments are alternating keys and
+// values. A *slog.Logger of the log/slog package satisfies this interface.
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Hooks are called during a migration, every hook is optional
+type Hooks struct {
+	// Plan is called with the new version and the statements once the migration is computed
+	Plan func(ctx context.Context, version int, stmts Statements)
+	// StatementStart is called before a statement is executed
+	StatementStart func(ctx context.Context, stmt Statement)
+	// StatementEnd is called after a statement is executed
+	StatementEnd func(ctx context.Context, stmt Statement, duration time.Duration, err error)
+	// Complete is called when the migration is finished or has failed
+	Complete func(ctx context.Context, version int, duration time.Duration, err error)
+}
+
+// Options configures a migration
+type Options struct {
+	// Logger logs the migration, nothing is logged when it's nil
+	Logger Logger
+	// Hooks are called during the migration
+	Hooks Hooks
+	// Online runs the migration in online mode when set, see MigrateOnline
+	Online *OnlineOptions
+}
+
+func (x Options) logger() Logger {
+	if x.Logger == nil {
+		return nopLogger{}
+	}
+
+	return x.Logger
+}
+
+func (x Options) plan(ctx context.Context, version int, stmts Statements) {
+	if x.Hooks.Plan != nil {
+		x.Hooks.Plan(ctx, version, stmts)
+	}
+}
+
+func (x Options) complete(ctx context.Context, version int, start time.Time, err error) {
+	if x.Hooks.Complete != nil {
+		x.Hooks.Complete(ctx, version, time.Since(start), err)
+	}
+}
+
+// execer is implemented by *sql.Tx and *sql.Conn
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// exec executes the statement and calls the statement hooks around it
+func (x Options) exec(ctx context.Context, db execer, stmt Statement) error {
+	if x.Hooks.StatementStart != nil {
+		x.Hooks.StatementStart(ctx, stmt)
+	}
+
+	start := time.Now()
+	_, err := db.ExecContext(ctx, stmt.SQL)
+
+	if x.Hooks.StatementEnd != nil {
+		x.Hooks.StatementEnd(ctx, stmt, time.Since(start), err)
+	}
+
+	return err
+}
+
+// nopLogger discards all logs
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// StdLogger logs to the standard logger of the log package
+type StdLogger struct{}
+
+// Info logs the message
+func (StdLogger) Info(msg string, args ...interface{}) {
+	log.Print(format(msg, args))
+}
+
+// Error logs the message as error
+func (StdLogger) Error(msg string, args ...interface{}) {
+	log.Print(`ERROR `, format(msg, args))
+}
+
+// format formats the message and its key value pairs, multiline values are
+// written on their own lines after the message
+func format(msg string, args []interface{}) string {
+	builder := &strings.Builder{}
+	builder.WriteString(msg) // nolint: errcheck
+
+	var blocks []string
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			fmt.Fprintf(builder, " %v", args[i]) // nolint: errcheck
+			break
+		}
+
+		value := fmt.Sprint(args[i+1])
+		if strings.Contains(value, "\n") {
+			blocks = append(blocks, value)
+			continue
+		}
+
+		fmt.Fprintf(builder, " %v=%s", args[i], value) // nolint: errcheck
+	}
+
+	for _, block := range blocks {
+		builder.WriteString("\n" + block) // nolint: errcheck
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/myceliums/assert"
+)
+
+func TestFormat(t *testing.T) {
+	as := assert.New(t)
+
+	as.Eq(`applying migration version=2`, format(`applying migration`, []interface{}{`version`, 2}))
+	as.Eq("applying migration version=2\nCREATE TABLE a();\n", format(`applying migration`, []interface{}{`version`, 2, `sql`, "CREATE TABLE a();\n"}))
+	as.Eq(`migration failed error=failed dangling`, format(`migration failed`, []interface{}{`error`, errors.New(`failed`), `dangling`}))
+}
+
+// slowExecer takes the delay to execute a statement and fails the statements with the sql of fail
+type slowExecer struct {
+	delay time.Duration
+	fail  string
+}
+
+func (x slowExecer) ExecContext(ctx context.Context, q string, args ...interface{}) (sql.Result, error) {
+	time.Sleep(x.delay)
+	if q == x.fail {
+		return nil, errors.New(`failed`)
+	}
+
+	return nil, nil
+}
+
+func TestHooks(t *testing.T) {
+	as := assert.New(t)
+
+	var calls []string
+	opts := Options{Hooks: Hooks{
+		StatementStart: func(ctx context.Context, stmt Statement) {
+			calls = append(calls, `start `+stmt.SQL)
+		},
+		StatementEnd: func(ctx context.Context, stmt Statement, duration time.Duration, err error) {
+			as.True(duration >= 5*time.Millisecond, duration)
+			calls = append(calls, fmt.Sprintf("end %s %v", stmt.SQL, err))
+		},
+		Complete: func(ctx context.Context, version int, duration time.Duration, err error) {
+			as.True(duration >= time.Second, duration)
+			calls = append(calls, fmt.Sprintf("complete %d %v", version, err))
+		},
+	}}
+
+	db := slowExecer{delay: 5 * time.Millisecond, fail: `b`}
+	as.NoError(opts.exec(context.Background(), db, Statement{SQL: `a`}))
+	as.Error(opts.exec(context.Background(), db, Statement{SQL: `b`}))
+	opts.complete(context.Background(), 3, time.Now().Add(-time.Second), errors.New(`failed`))
+
+	as.Cmp([]string{`start a`, `end a <nil>`, `start b`, `end b failed`, `complete 3 failed`}, calls)
+
+	// without hooks the statements are only executed
+	as.NoError(Options{}.exec(context.Background(), db, Statement{SQL: `a`}))
+	Options{}.complete(context.Background(), 3, time.Now(), nil)
+}
@@ -0,0 +1,374 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/myceliums/gdb/dialect"
+)
+
+// Change is a single change of a migration plan, which fields are set depends on its kind
+type Change struct {
+	// Kind is the kind of the change
+	Kind Kind `json:"kind"`
+	// Object is the name of the schema, enum, table, column (table.column),
+	// constraint or view the change applies to
+	Object string `json:"object"`
+	// Destructive is true when the change can remove data, which includes a type
+	// change of which the using expression rewrites the values
+	Destructive bool `json:"destructive,omitempty"`
+
+	// Name is the name of the schema, enum, view or unique constraint
+	Name string `json:"name,omitempty"`
+	// To is the new name of a renamed enum
+	To     string `json:"to,omitempty"`
+	Table  string `json:"table,omitempty"`
+	Column string `json:"column,omitempty"`
+	// Type and Size are the (new) type of a column, From is its previous type
+	Type string `json:"type,omitempty"`
+	Size int    `json:"size,omitempty"`
+	From string `json:"from,omitempty"`
+	// Using is the expression converting the values of a column to its new type,
+	// when empty the conversion of the dialect is used
+	Using string `json:"using,omitempty"`
+	// Expression is the default, the check or the backfill expression
+	Expression string `json:"expression,omitempty"`
+	// Value is the enum value to add or the value to backfill, the zero value
+	// of the type is backfilled when both the expression and value are empty
+	Value string `json:"value,omitempty"`
+	// Before is the existing enum value a new value is inserted before
+	Before string `json:"before,omitempty"`
+	// Values are the values of a new enum
+	Values []string `json:"values,omitempty"`
+	// Columns are the columns of a key or of a seed row
+	Columns []string `json:"columns,omitempty"`
+	// Keys are the primary key columns identifying a seed row
+	Keys []string `json:"keys,omitempty"`
+	// Row are the values of the columns of a seed row, or of its keys when it's deleted
+	Row             []*string `json:"row,omitempty"`
+	ReferenceTable  string    `json:"reference_table,omitempty"`
+	ReferenceColumn string    `json:"reference_column,omitempty"`
+	Query           string    `json:"query,omitempty"`
+	Materialized    bool      `json:"materialized,omitempty"`
+}
+
+// String returns the kind and object of the change
+func (x Change) String() string {
+	return fmt.Sprintf("%s %s", x.Kind, x.Object)
+}
+
+// SQL returns the statements of the change in the given dialect, every statement
+// is executed on its own so a failing statement can be reported
+func (x Change) SQL(dialect dialect.Dialect) []string {
+	switch x.Kind {
+	case KindSetAutoIncrement:
+		return dialect.SetAutoIncrement(x.Table, x.Column)
+	case KindDropAutoIncrement:
+		return dialect.UnsetAutoIncrement(x.Table, x.Column)
+	case KindAlterCheck:
+		return []string{dialect.DropCheck(x.Table, x.Column), dialect.AddCheck(x.Table, x.Column, x.Expression)}
+	case KindAlterPrimaryKey:
+		return []string{dialect.DropPrimaryKey(x.Table), dialect.AddPrimaryKey(x.Table, x.Columns)}
+	case KindAlterUnique:
+		return []string{dialect.DropUnique(x.Name, x.Table), dialect.AddUnique(x.Name, x.Table, x.Columns)}
+	case KindAlterForeignKey:
+		return []string{
+			dialect.DropForeignKey(x.Table, x.Column),
+			dialect.AddForeignKey(x.Table, x.Column, x.ReferenceTable, x.ReferenceColumn),
+		}
+	}
+
+	return []string{x.statement(dialect)}
+}
+
+// statement returns the sql of a change which is a single statement
+func (x Change) statement(dialect dialect.Dialect) string {
+	switch x.Kind {
+	case KindAddSchema:
+		return dialect.AddSchema(x.Name)
+	case KindDropSchema:
+		return dialect.DropSchema(x.Name)
+	case KindAddEnum:
+		return dialect.AddEnum(x.Name, x.Values)
+	case KindAddEnumValue:
+		if x.Before != `` {
+			return dialect.InsertEnum(x.Name, x.Value, x.Before)
+		}
+		return dialect.AppendEnum(x.Name, x.Value)
+	case KindRenameEnum:
+		return dialect.RenameEnum(x.Name, x.To)
+	case KindDropEnum:
+		return dialect.DropEnum(x.Name)
+	case KindAddTable:
+		return dialect.AddTable(x.Table, false)
+	case KindDropTable:
+		return dialect.DropTable(x.Table)
+	case KindAddColumn:
+		return dialect.AddColumn(x.Table, x.Column, x.Type, x.Size)
+	case KindAlterType:
+		using := x.Using
+		if using == `` {
+			using, _ = dialect.Cast(x.Column, x.From, x.Type)
+		}
+		return dialect.UpdateColumn(x.Table, x.Column, x.Type, x.Size, using)
+	case KindDropColumn:
+		return dialect.DropColumn(x.Table, x.Column)
+	case KindBackfill:
+		value := x.Expression
+		switch {
+		case value != ``:
+		case x.Value != ``:
+			value = dialect.QuoteLiteral(x.Value)
+		default:
+			value = dialect.ZeroValue(x.Type)
+		}
+		return dialect.Backfill(x.Table, x.Column, value)
+	case KindSetNotNull:
+		return dialect.SetNotNull(x.Table, x.Column)
+	case KindDropNotNull:
+		return dialect.DeleteNotNull(x.Table, x.Column)
+	case KindSetDefault:
+		return dialect.SetDefault(x.Table, x.Column, x.Expression)
+	case KindDropDefault:
+		return dialect.DropDefault(x.Table, x.Column)
+	case KindAddCheck:
+		return dialect.AddCheck(x.Table, x.Column, x.Expression)
+	case KindDropCheck:
+		return dialect.DropCheck(x.Table, x.Column)
+	case KindAddPrimaryKey:
+		return dialect.AddPrimaryKey(x.Table, x.Columns)
+	case KindDropPrimaryKey:
+		return dialect.DropPrimaryKey(x.Table)
+	case KindAddUnique:
+		return dialect.AddUnique(x.Name, x.Table, x.Columns)
+	case KindDropUnique:
+		return dialect.DropUnique(x.Name, x.Table)
+	case KindAddForeignKey:
+		return dialect.AddForeignKey(x.Table, x.Column, x.ReferenceTable, x.ReferenceColumn)
+	case KindDropForeignKey:
+		return dialect.DropForeignKey(x.Table, x.Column)
+	case KindAddView:
+		return dialect.AddView(x.Name, x.Query, x.Materialized)
+	case KindDropView:
+		return dialect.DropView(x.Name, x.Materialized)
+	case KindUpsertRow:
+		return dialect.UpsertRow(x.Table, x.Keys, x.Columns, x.Row)
+	case KindResetAutoIncrement:
+		return dialect.ResetAutoIncrement(x.Table, x.Column)
+	case KindDeleteRow:
+		return dialect.DeleteRow(x.Table, x.Keys, x.Row)
+	}
+
+	return ``
+}
+
+// Plan is the ordered list of changes which migrate a database from one model to another
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// Statements returns the statements of the changes in the given dialect
+func (x Plan) Statements(dialect dialect.Dialect) Statements {
+	stmts := &Statements{}
+	for _, change := range x.Changes {
+		for _, q := range change.SQL(dialect) {
+			stmts.add(change.Kind, change.Object, change.Destructive, q)
+		}
+	}
+
+	return *stmts
+}
+
+// SQL returns the sql of the changes in the given dialect
+func (x Plan) SQL(dialect dialect.Dialect) string {
+	return x.Statements(dialect).String()
+}
+
+// Filter returns a plan with only the changes for which keep returns true
+func (x Plan) Filter(keep func(Change) bool) Plan {
+	plan := Plan{}
+	for _, change := range x.Changes {
+		if keep(change) {
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+
+	return plan
+}
+
+// Destructive returns true when one of the changes can remove data
+func (x Plan) Destructive() bool {
+	for _, change := range x.Changes {
+		if change.Destructive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the changes one per line
+func (x Plan) String() string {
+	builder := &strings.Builder{}
+	for _, change := range x.Changes {
+		builder.WriteString(change.String() + "\n") // nolint: errcheck
+	}
+
+	return builder.String()
+}
+
+func (x *Plan) add(change Change) {
+	change.Destructive = change.Kind.Destructive() || (change.Kind == KindAlterType && change.Using != ``)
+	x.Changes = append(x.Changes, change)
+}
+
+// Initial returns the plan to model an empty database after the given model
+func Initial(mdl Model) Plan {
+	plan := &Plan{}
+	for _, schema := range mdl.Schemas {
+		plan.add(Change{Kind: KindAddSchema, Object: schema, Name: schema})
+	}
+
+	for _, name := range enumNames(mdl.Enums) {
+		enum := mdl.Enums[name]
+		plan.add(Change{Kind: KindAddEnum, Object: enum.Name, Name: enum.Name, Values: enum.Values})
+	}
+
+	for _, table := range tableNames(mdl.Tables) {
+		addTable(plan, table, mdl.Tables[table])
+	}
+
+	for _, table := range constraintNames(mdl.Primaries) {
+		cols := mdl.Primaries[table]
+		var colNames []string
+		for _, col := range cols {
+			colNames = append(colNames, col.Name)
+		}
+		plan.add(Change{Kind: KindAddPrimaryKey, Object: cols[0].Table, Table: cols[0].Table, Columns: colNames})
+	}
+
+	for _, id := range constraintNames(mdl.Uniques) {
+		cols := mdl.Uniques[id]
+		var colNames []string
+		for _, col := range cols {
+			colNames = append(colNames, col.Name)
+		}
+		plan.add(Change{Kind: KindAddUnique, Object: id, Name: id, Table: cols[0].Table, Columns: colNames})
+	}
+
+	for _, name := range foreignNames(mdl.Foreigns) {
+		plan.add(foreignKey(KindAddForeignKey, mdl.Foreigns[name]))
+	}
+
+	for _, view := range sortViews(mdl.Views) {
+		plan.add(Change{Kind: KindAddView, Object: view.Name, Name: view.Name, Query: view.Query, Materialized: view.Materialized})
+	}
+
+	diffSeeds(plan, Model{}, mdl)
+
+	return *plan
+}
+
+// Diff returns the plan which resolves the differential safely between 2 models
+func Diff(prev, curr Model) Plan {
+	prev = prev.clone()
+
+	plan := &Plan{}
+	for _, schema := range curr.Schemas {
+		if !contains(prev.Schemas, schema) {
+			plan.add(Change{Kind: KindAddSchema, Object: schema, Name: schema})
+		}
+	}
+
+	// the seeds are compared before the previous model is consumed by the comparisons below
+	seeds := &Plan{}
+	diffSeeds(seeds, prev, curr)
+
+	recreate := recreatedViews(prev, curr)
+	dropped := sortViews(prev.Views)
+	for i := len(dropped) - 1; i >= 0; i-- {
+		if view := dropped[i]; recreate[view.Name] {
+			plan.add(Change{Kind: KindDropView, Object: view.Name, Name: view.Name, Materialized: view.Materialized})
+		}
+	}
+
+	replaced := diffEnums(plan, prev, curr)
+
+	compareTables(plan, curr.Tables, prev.Tables)
+
+	compareKeys(plan, prev, curr)
+
+	for _, view := range sortViews(curr.Views) {
+		if _, ok := prev.Views[view.Name]; !ok || recreate[view.Name] {
+			plan.add(Change{Kind: KindAddView, Object: view.Name, Name: view.Name, Query: view.Query, Materialized: view.Materialized})
+		}
+	}
+
+	plan.Changes = append(plan.Changes, seeds.Changes...)
+
+	for _, name := range enumNames(prev.Enums) {
+		enum := prev.Enums[name]
+		plan.add(Change{Kind: KindDropEnum, Object: enum.Name, Name: enum.Name})
+	}
+
+	for _, name := range replaced {
+		plan.add(Change{Kind: KindDropEnum, Object: name, Name: name})
+	}
+
+	for _, schema := range prev.Schemas {
+		if !contains(curr.Schemas, schema) {
+			plan.add(Change{Kind: KindDropSchema, Object: schema, Name: schema})
+		}
+	}
+
+	return *plan
+}
+
+// clone copies the maps of the model which are consumed while diffing
+func (x Model) clone() Model {
+	enums := map[string]*Enum{}
+	for k, v := range x.Enums {
+		enums[k] = v
+	}
+	x.Enums = enums
+
+	tables := map[string]map[string]*Column{}
+	for k, cols := range x.Tables {
+		tables[k] = map[string]*Column{}
+		for name, col := range cols {
+			tables[k][name] = col
+		}
+	}
+	x.Tables = tables
+
+	primaries := map[string][]*Column{}
+	for k, v := range x.Primaries {
+		primaries[k] = append([]*Column{}, v...)
+	}
+	x.Primaries = primaries
+
+	uniques := map[string][]*Column{}
+	for k, v := range x.Uniques {
+		uniques[k] = append([]*Column{}, v...)
+	}
+	x.Uniques = uniques
+
+	foreigns := map[string]*Column{}
+	for k, v := range x.Foreigns {
+		foreigns[k] = v
+	}
+	x.Foreigns = foreigns
+
+	return x
+}
+
+func foreignKey(kind Kind, col *Column) Change {
+	return Change{
+		Kind:            kind,
+		Object:          columnObject(col.Table, col.Name),
+		Table:           col.Table,
+		Column:          col.Name,
+		ReferenceTable:  col.Ref.Table,
+		ReferenceColumn: col.Ref.Name,
+	}
+}
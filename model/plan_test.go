@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/dialect"
+)
+
+func TestDiff(t *testing.T) {
+	as := assert.New(t)
+	prev := initModel(t, []byte(`
+accounts:
+  id: int primary
+  email: varchar
+  nickname: varchar
+`))
+	curr := initModel(t, []byte(`
+accounts:
+  id: int primary
+  email: varchar(100)
+  active: bool not null
+`))
+
+	plan := Diff(*prev, *curr)
+	as.Eq(3, len(prev.Tables[`accounts`]))
+	as.Eq(1, len(prev.Primaries))
+
+	kinds := map[Kind]Change{}
+	for _, change := range plan.Changes {
+		kinds[change.Kind] = change
+	}
+
+	as.Eq(`varchar`, kinds[KindAlterType].From)
+	as.Eq(100, kinds[KindAlterType].Size)
+	as.Eq(`boolean`, kinds[KindBackfill].Type)
+	as.True(kinds[KindDropColumn].Destructive)
+	as.True(plan.Destructive())
+	as.False(plan.Filter(func(c Change) bool { return !c.Destructive }).Destructive())
+
+	dia := dialect.GetByDriver(`postgres`)
+	sq := plan.SQL(dia)
+	as.Eq(`ALTER TABLE "accounts" ADD COLUMN "active" BOOLEAN;
+UPDATE "accounts" SET "active" = false WHERE "active" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "active" SET NOT NULL;
+ALTER TABLE "accounts" ALTER COLUMN "email" TYPE VARCHAR(100);
+ALTER TABLE "accounts" DROP COLUMN "nickname";
+`, sq)
+
+	out, err := json.Marshal(plan)
+	as.NoError(err)
+
+	var decoded Plan
+	as.NoError(json.Unmarshal(out, &decoded))
+	as.Eq(sq, decoded.SQL(dia))
+}
+
+func TestPlanSummary(t *testing.T) {
+	as := assert.New(t)
+	prev := initModel(t, []byte(`
+accounts:
+  id: int primary
+  email: varchar
+  age: int
+
+legacy:
+  id: int primary
+
+status:
+- active
+`))
+	curr := initModel(t, []byte(`
+accounts:
+  id: int primary
+  email: varchar(100) unique(email)
+  name: varchar
+
+posts:
+  id: int primary
+  account_id: accounts.id not null
+
+status:
+- active
+- blocked
+`))
+
+	summary := Diff(*prev, *curr).Summary()
+	as.Cmp([]string{`posts`}, summary.Tables.Added)
+	as.Cmp([]string{`legacy`}, summary.Tables.Removed)
+	as.Cmp([]string{`accounts`}, summary.Tables.Changed)
+	as.Cmp([]string{`accounts.name`}, summary.Columns.Added)
+	as.Cmp([]string{`accounts.age`}, summary.Columns.Removed)
+	as.Cmp([]string{`accounts.email`}, summary.Columns.Changed)
+	as.Cmp([]string{`unique email on accounts`}, summary.Constraints.Added)
+	as.Cmp([]string{`status`}, summary.Enums.Changed)
+	as.True(summary.Views.Empty())
+
+	as.Eq(`Enums:
+  ~ status
+Tables:
+  + posts
+  - legacy
+  ~ accounts
+Columns:
+  + accounts.name
+  - accounts.age
+  ~ accounts.email
+Constraints:
+  + unique email on accounts
+`, summary.String())
+	as.Eq("No changes\n", Diff(*curr, *curr).Summary().String())
+
+	// a recreated enum is changed instead of added and removed
+	prev = initModel(t, []byte("logs:\n  level: level\nlevel:\n- debug\n- info\n"))
+	curr = initModel(t, []byte("logs:\n  level: level\nlevel:\n  recreate: true\n  enum:\n  - info\n"))
+	as.Eq("Enums:\n  ~ level\nTables:\n  ~ logs\nColumns:\n  ~ logs.level\n", Diff(*prev, *curr).Summary().String())
+}
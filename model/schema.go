@@ -0,0 +1,117 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// columnPattern is the grammar of a column definition: a type, which is a primitive
+// type, an enum or a referenced column, with an optional size followed by its modifiers
+const columnPattern = `^\s*[A-Za-z_][\w\-]*(\.[A-Za-z_][\w\-]*){0,2}(\(\d{1,3}\))?` +
+	`(\s+(primary key|primarykey|primary|auto ?increment|unique(\(\w+\))?|not ?null|(default|check|using)\(.*\)))*\s*$`
+
+// JSONSchema returns the JSON Schema of the configuration, which can be used by
+// editors to complete and validate the configuration. The primitive types are
+// the aliases the model accepts.
+func JSONSchema() ([]byte, error) {
+	types := PrimitiveTypes()
+
+	ref := func(name string) map[string]interface{} {
+		return map[string]interface{}{`$ref`: `#/definitions/` + name}
+	}
+
+	name := map[string]interface{}{`type`: `string`, `pattern`: nameReg.String()}
+
+	examples := append(append([]string{}, types...), `serial primary`, `varchar(100) unique not null`, `accounts.id not null`, `timestamp default(NOW())`)
+
+	schema := map[string]interface{}{
+		`$schema`:     `http://json-schema.org/draft-07/schema#`,
+		`title`:       `gdb configuration`,
+		`description`: `The tables, enums, views and schemas of a database managed by gdb`,
+		`type`:        `object`,
+		`properties`: map[string]interface{}{
+			includeKey: map[string]interface{}{
+				`description`: `The configuration files and directories to include, relative to this file`,
+				`anyOf`: []interface{}{
+					map[string]interface{}{`type`: `string`},
+					map[string]interface{}{`type`: `array`, `items`: map[string]interface{}{`type`: `string`}},
+				},
+			},
+		},
+		`propertyNames`:        name,
+		`additionalProperties`: map[string]interface{}{`anyOf`: []interface{}{ref(`table`), ref(`enum`), ref(`recreatableEnum`), ref(`view`), ref(`schema`)}},
+		`definitions`: map[string]interface{}{
+			`column`: map[string]interface{}{
+				`description`: `A column definition: <type>[(size)] [primary] [auto increment] [unique[(name)]] [not null] [default(value)] [check(expression)] [using(expression)]. ` +
+					`The type is one of ` + strings.Join(types, `, `) + `, an enum or a referenced column as table.column`,
+				`type`:     `string`,
+				`pattern`:  columnPattern,
+				`examples`: examples,
+			},
+			`table`: map[string]interface{}{
+				`description`: `A table with its columns`,
+				`type`:        `object`,
+				`properties`: map[string]interface{}{
+					seedKey: map[string]interface{}{
+						`description`: `The rows that are inserted or updated by their primary key on every migration`,
+						`type`:        `array`,
+						`items`:       map[string]interface{}{`type`: `object`},
+					},
+					descriptionKey: map[string]interface{}{
+						`description`:          `The descriptions of the columns`,
+						`type`:                 `object`,
+						`additionalProperties`: map[string]interface{}{`type`: `string`},
+					},
+				},
+				`propertyNames`:        name,
+				`additionalProperties`: ref(`column`),
+				`minProperties`:        1,
+			},
+			`enum`: map[string]interface{}{
+				`description`: `An enum with its values, values can only be appended unless the enum may be recreated`,
+				`type`:        `array`,
+				`items`:       map[string]interface{}{`type`: `string`, `minLength`: 1},
+				`minItems`:    1,
+			},
+			`recreatableEnum`: map[string]interface{}{
+				`description`: `An enum which may be recreated to remove or reorder its values`,
+				`type`:        `object`,
+				`required`:    []string{enumKey},
+				`properties`: map[string]interface{}{
+					enumKey:     ref(`enum`),
+					recreateKey: map[string]interface{}{`type`: `boolean`},
+				},
+				`additionalProperties`: false,
+			},
+			`view`: map[string]interface{}{
+				`description`: `A (materialized) view with the types of its columns`,
+				`type`:        `object`,
+				`required`:    []string{viewKey},
+				`properties`: map[string]interface{}{
+					viewKey:         map[string]interface{}{`description`: `The query of the view`, `type`: `string`},
+					materializedKey: map[string]interface{}{`type`: `boolean`},
+				},
+				`propertyNames`:        name,
+				`additionalProperties`: ref(`column`),
+			},
+			`schema`: map[string]interface{}{
+				`description`:          `A schema with its tables, enums and views`,
+				`type`:                 `object`,
+				`propertyNames`:        name,
+				`additionalProperties`: map[string]interface{}{`anyOf`: []interface{}{ref(`table`), ref(`enum`), ref(`recreatableEnum`), ref(`view`)}},
+				`minProperties`:        1,
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(``, `  `)
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
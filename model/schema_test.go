@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"github.com/myceliums/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	as := assert.New(t)
+
+	out, err := JSONSchema()
+	as.NoError(err)
+
+	shipped, err := ioutil.ReadFile(`../schema.json`)
+	as.NoError(err)
+	as.Eq(string(out), string(shipped), `schema.json is outdated, run: go run ./cmd/gdb schema -o schema.json`)
+
+	var schema struct {
+		Definitions struct {
+			Column struct {
+				Pattern  string   `json:"pattern"`
+				Examples []string `json:"examples"`
+			} `json:"column"`
+		} `json:"definitions"`
+	}
+	as.NoError(json.Unmarshal(out, &schema))
+
+	column := regexp.MustCompile(schema.Definitions.Column.Pattern)
+	examples := map[string]bool{}
+	for _, example := range schema.Definitions.Column.Examples {
+		examples[example] = true
+		as.True(column.MatchString(example), example)
+	}
+
+	for alias := range primitiveTypesAliases() {
+		as.True(examples[alias], alias)
+		as.True(column.MatchString(alias+`(10) primary key auto increment unique(x) not null default('a') check(x>0)`), alias)
+	}
+
+	for _, def := range []string{`accounts.id notnull`, `auth.accounts.id`, `mood default('ok')`} {
+		as.True(column.MatchString(def), def)
+	}
+
+	for _, file := range []string{`testmodel.yml`, `testnextmodel.yml`} {
+		in, err := ioutil.ReadFile(file)
+		as.NoError(err)
+
+		conf, err := newConfig(in)
+		as.NoError(err)
+		for table, cols := range conf.Tables {
+			for name, def := range cols {
+				as.True(column.MatchString(def), table, name, def)
+			}
+		}
+	}
+
+	for _, def := range []string{`int nullable`, `varchar(1000)`, `int primary keys`, ``} {
+		as.False(column.MatchString(def), def)
+	}
+}
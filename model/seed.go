@@ -0,0 +1,253 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// seedKey is the key of the seed rows in a table configuration
+const seedKey = `seed`
+
+// timestampLayouts are the accepted layouts of timestamps in seed data, a time without
+// a date isn't accepted since time and date are aliases of a TIMESTAMP column
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	`2006-01-02 15:04:05`,
+	`2006-01-02T15:04:05`,
+	`2006-01-02`,
+}
+
+// Row is a row of seed data, nil values are inserted as NULL
+type Row map[string]*string
+
+// Key returns the values of the given columns which identify the row
+func (x Row) Key(columns []string) []*string {
+	values := make([]*string, len(columns))
+	for i, col := range columns {
+		values[i] = x[col]
+	}
+
+	return values
+}
+
+// Columns returns the sorted column names of the row
+func (x Row) Columns() []string {
+	var cols []string
+	for col := range x {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	return cols
+}
+
+func (x Row) id(columns []string) string {
+	var parts []string
+	for _, v := range x.Key(columns) {
+		parts = append(parts, strconv.Quote(*v))
+	}
+
+	return strings.Join(parts, `,`)
+}
+
+func (x Row) equal(row Row) bool {
+	if len(x) != len(row) {
+		return false
+	}
+
+	for col, v := range x {
+		w, ok := row[col]
+		if !ok || (v == nil) != (w == nil) || (v != nil && *v != *w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRows(table string, list []interface{}) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	for i, item := range list {
+		m, ok := item.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("seed row %d of table %s should be a map of columns, but has %T", i+1, table, item)
+		}
+
+		row := map[string]interface{}{}
+		for k, v := range m {
+			row[fmt.Sprint(k)] = v
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func appendSeeds(m Model, seeds map[string][]map[string]interface{}) (Model, error) {
+	for table, rows := range seeds {
+		cols := m.Tables[table]
+
+		keys := m.PrimaryKey(table)
+		if len(keys) < 1 && len(rows) > 0 {
+			return m, fmt.Errorf("table %s has seed data but no primary key to identify the rows", table)
+		}
+
+		ids := map[string]int{}
+		for i, raw := range rows {
+			row := Row{}
+			for name, v := range raw {
+				col, ok := cols[name]
+				if !ok {
+					return m, fmt.Errorf("seed row %d of table %s has unknown column %s", i+1, table, name)
+				}
+
+				if v == nil {
+					row[name] = nil
+					continue
+				}
+
+				value := fmt.Sprint(v)
+				if err := validateValue(col, value); err != nil {
+					return m, fmt.Errorf("seed row %d of table %s: %v", i+1, table, err)
+				}
+				row[name] = &value
+			}
+
+			for name, col := range cols {
+				value, ok := row[name]
+				if col.Primary != `` && value == nil {
+					return m, fmt.Errorf("seed row %d of table %s is missing primary key column %s", i+1, table, name)
+				}
+
+				required := col.NotNull && col.Default == `` && !col.AutoIncement
+				if (required && !ok) || (col.NotNull && ok && value == nil) {
+					return m, fmt.Errorf("seed row %d of table %s is missing not null column %s", i+1, table, name)
+				}
+			}
+
+			id := row.id(keys)
+			if j, ok := ids[id]; ok {
+				return m, fmt.Errorf("seed rows %d and %d of table %s have the same primary key", j+1, i+1, table)
+			}
+			ids[id] = i
+
+			m.Seeds[table] = append(m.Seeds[table], row)
+		}
+	}
+
+	return m, nil
+}
+
+// validateValue checks if the given value can be stored in the column
+func validateValue(col *Column, value string) error {
+	var err error
+	switch datatype := underlying(col.Datatype).(type) {
+	case *Enum:
+		if !contains(datatype.Values, value) {
+			err = fmt.Errorf("'%s' is not a value of enum %s", value, datatype.Name)
+		}
+	default:
+		switch col.Type() {
+		case `int`, `bigint`, `smallint`:
+			_, err = strconv.ParseInt(value, 10, 64)
+		case `float`, `double`:
+			_, err = strconv.ParseFloat(value, 64)
+		case `boolean`:
+			_, err = strconv.ParseBool(value)
+		case `timestamp`:
+			err = fmt.Errorf("'%s' is not a valid timestamp", value)
+			for _, layout := range timestampLayouts {
+				if _, perr := time.Parse(layout, value); perr == nil {
+					err = nil
+					break
+				}
+			}
+		case `varchar`:
+			if size := underlyingColumn(col).Size; size > 0 && len([]rune(value)) > size {
+				err = fmt.Errorf("'%s' is longer than %d characters", value, size)
+			}
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value for column %s: %v", col.Name, err)
+	}
+
+	return nil
+}
+
+// underlying returns the datatype a (foreign key) datatype resolves to
+func underlying(datatype DataType) DataType {
+	for {
+		col, ok := datatype.(*Column)
+		if !ok {
+			return datatype
+		}
+		datatype = col.Datatype
+	}
+}
+
+// underlyingColumn returns the column that a foreign key column resolves to
+func underlyingColumn(col *Column) *Column {
+	for col.Ref != nil {
+		col = col.Ref
+	}
+
+	return col
+}
+
+// PrimaryKey returns the sorted primary key column names of the given table
+func (x Model) PrimaryKey(table string) []string {
+	var names []string
+	for _, col := range x.Primaries[table] {
+		names = append(names, col.Name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// sortTables returns the given tables ordered so that every table comes after
+// the tables it references, tables that reference each other keep their sorted order
+func sortTables(m Model, tables []string) []string {
+	sort.Strings(tables)
+
+	set := map[string]bool{}
+	for _, table := range tables {
+		set[table] = true
+	}
+
+	var sorted []string
+	visited := map[string]bool{}
+
+	var visit func(table string)
+	visit = func(table string) {
+		if !set[table] || visited[table] {
+			return
+		}
+		visited[table] = true
+
+		var refs []string
+		for _, col := range m.Tables[table] {
+			if col.Ref != nil && col.Ref.Table != table {
+				refs = append(refs, col.Ref.Table)
+			}
+		}
+		sort.Strings(refs)
+
+		for _, ref := range refs {
+			visit(ref)
+		}
+
+		sorted = append(sorted, table)
+	}
+
+	for _, table := range tables {
+		visit(table)
+	}
+
+	return sorted
+}
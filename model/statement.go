@@ -0,0 +1,133 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the kind of change a statement makes
+type Kind string
+
+// The kinds of statements
+const (
+	KindAddSchema          Kind = `AddSchema`
+	KindDropSchema         Kind = `DropSchema`
+	KindAddEnum            Kind = `AddEnum`
+	KindAddEnumValue       Kind = `AddEnumValue`
+	KindRenameEnum         Kind = `RenameEnum`
+	KindDropEnum           Kind = `DropEnum`
+	KindAddTable           Kind = `AddTable`
+	KindDropTable          Kind = `DropTable`
+	KindAddColumn          Kind = `AddColumn`
+	KindAlterType          Kind = `AlterType`
+	KindDropColumn         Kind = `DropColumn`
+	KindBackfill           Kind = `Backfill`
+	KindSetAutoIncrement   Kind = `SetAutoIncrement`
+	KindDropAutoIncrement  Kind = `DropAutoIncrement`
+	KindResetAutoIncrement Kind = `ResetAutoIncrement`
+	KindSetNotNull         Kind = `SetNotNull`
+	KindDropNotNull        Kind = `DropNotNull`
+	KindSetDefault         Kind = `SetDefault`
+	KindDropDefault        Kind = `DropDefault`
+	KindAddCheck           Kind = `AddCheck`
+	KindAlterCheck         Kind = `AlterCheck`
+	KindValidateCheck      Kind = `ValidateCheck`
+	KindDropCheck          Kind = `DropCheck`
+	KindAddPrimaryKey      Kind = `AddPrimaryKey`
+	KindAlterPrimaryKey    Kind = `AlterPrimaryKey`
+	KindDropPrimaryKey     Kind = `DropPrimaryKey`
+	KindAddUnique          Kind = `AddUnique`
+	KindAlterUnique        Kind = `AlterUnique`
+	KindDropUnique         Kind = `DropUnique`
+	KindAddForeignKey      Kind = `AddForeignKey`
+	KindAlterForeignKey    Kind = `AlterForeignKey`
+	KindValidateForeignKey Kind = `ValidateForeignKey`
+	KindDropForeignKey     Kind = `DropForeignKey`
+	KindAddIndex           Kind = `AddIndex`
+	KindDropIndex          Kind = `DropIndex`
+	KindAddView            Kind = `AddView`
+	KindDropView           Kind = `DropView`
+	KindUpsertRow          Kind = `UpsertRow`
+	KindDeleteRow          Kind = `DeleteRow`
+)
+
+// Destructive returns true when statements of the kind can remove data
+func (x Kind) Destructive() bool {
+	switch x {
+	case KindDropSchema, KindDropTable, KindDropColumn, KindDeleteRow:
+		return true
+	}
+
+	return false
+}
+
+// Statement is a single statement of a migration
+type Statement struct {
+	// Kind is the kind of change the statement makes
+	Kind Kind `json:"kind"`
+	// Object is the name of the schema, enum, table, column (table.column),
+	// constraint or view the statement changes
+	Object string `json:"object"`
+	// SQL is the sql of the statement
+	SQL string `json:"sql"`
+	// Destructive is true when the statement can remove data
+	Destructive bool `json:"destructive"`
+}
+
+// Statements is the ordered list of statements of a migration
+type Statements []Statement
+
+// String returns the sql of all statements
+func (x Statements) String() string {
+	builder := &strings.Builder{}
+	for _, stmt := range x {
+		builder.WriteString(stmt.SQL) // nolint: errcheck
+	}
+
+	return builder.String()
+}
+
+// split returns the statements of the kind and the other statements in their order
+func (x Statements) split(kind Kind) (matched, others Statements) {
+	for _, stmt := range x {
+		if stmt.Kind == kind {
+			matched = append(matched, stmt)
+		} else {
+			others = append(others, stmt)
+		}
+	}
+
+	return matched, others
+}
+
+// add appends a statement, statements without sql are left out
+func (x *Statements) add(kind Kind, object string, destructive bool, q string) {
+	if q == `` {
+		return
+	}
+
+	*x = append(*x, Statement{Kind: kind, Object: object, SQL: q, Destructive: destructive})
+}
+
+// StatementError is returned when a statement of a migration fails
+type StatementError struct {
+	// Position is the 1 based position of the statement in the migration
+	Position int
+	// Total is the number of statements of the migration
+	Total     int
+	Statement Statement
+	Err       error
+}
+
+func (x *StatementError) Error() string {
+	return fmt.Sprintf("statement %d of %d (%s %s) failed: %v\n%s", x.Position, x.Total, x.Statement.Kind, x.Statement.Object, x.Err, strings.TrimSpace(x.Statement.SQL))
+}
+
+// Unwrap returns the error of the database
+func (x *StatementError) Unwrap() error {
+	return x.Err
+}
+
+func columnObject(table, name string) string {
+	return table + `.` + name
+}
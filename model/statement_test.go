@@ -0,0 +1,117 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/dialect"
+)
+
+func TestUpgradeStatements(t *testing.T) {
+	as := assert.New(t)
+	prev := initModel(t, []byte(`
+accounts:
+  id: int primary
+  email: varchar
+  nickname: varchar
+`))
+	curr := initModel(t, []byte(`
+accounts:
+  id: int primary
+  email: varchar(100)
+  created_at: timestamp
+`))
+
+	stmts := UpgradeStatements(dialect.GetByDriver(`postgres`), *prev, *curr)
+
+	objects := map[Kind]string{}
+	for _, stmt := range stmts {
+		as.True(stmt.SQL != ``)
+		as.Eq(stmt.Kind.Destructive(), stmt.Destructive)
+		objects[stmt.Kind] = stmt.Object
+	}
+
+	as.Eq(3, len(stmts))
+	as.Eq(`accounts.email`, objects[KindAlterType])
+	as.Eq(`accounts.created_at`, objects[KindAddColumn])
+	as.Eq(`accounts.nickname`, objects[KindDropColumn])
+}
+
+func TestSingleStatements(t *testing.T) {
+	as := assert.New(t)
+	prev := initModel(t, []byte(`
+accounts:
+  id: int primary
+  number: int auto increment
+  code: varchar
+  email: varchar unique(contact)
+  age: int check(age>0)
+
+teams:
+  id: int primary
+  name: varchar
+
+members:
+  account: accounts.id
+  team: int
+`))
+	curr := initModel(t, []byte(`
+accounts:
+  id: int
+  number: int
+  code: int using(code::int)
+  email: varchar unique(contact)
+  phone: varchar unique(contact)
+  age: int check(age>18)
+  login: int primary auto increment
+
+teams:
+  id: int primary
+  name: varchar
+
+members:
+  account: accounts.login
+  team: teams.id
+`))
+
+	dia := dialect.GetByDriver(`postgres`)
+	stmts := UpgradeStatements(dia, *prev, *curr)
+	online, steps := OnlineUpgradeStatements(dia, *prev, *curr)
+	for _, step := range steps {
+		online = append(online, step...)
+	}
+
+	// the reference of a foreign key isn't changed by the models above
+	fk := Change{Kind: KindAlterForeignKey, Object: `members.team`, Table: `members`, Column: `team`, ReferenceTable: `teams`, ReferenceColumn: `id`}
+	stmts = append(stmts, Plan{Changes: []Change{fk}}.Statements(dia)...)
+
+	kinds := map[Kind]int{}
+	for _, stmt := range append(stmts, online...) {
+		as.Eq(1, strings.Count(stmt.SQL, `;`), stmt.SQL)
+		kinds[stmt.Kind]++
+
+		if stmt.Kind == KindAlterType {
+			as.Eq(stmt.Object == `accounts.code`, stmt.Destructive, stmt.Object)
+		}
+	}
+
+	for _, kind := range []Kind{KindSetAutoIncrement, KindDropAutoIncrement, KindAlterCheck, KindAlterPrimaryKey, KindAlterUnique, KindAlterForeignKey} {
+		as.True(kinds[kind] > 1, kind)
+	}
+}
+
+func TestStatementError(t *testing.T) {
+	as := assert.New(t)
+	cause := errors.New(`relation "accounts" does not exist`)
+	err := &StatementError{
+		Position:  2,
+		Total:     5,
+		Statement: Statement{Kind: KindAddColumn, Object: `accounts.email`, SQL: "ALTER TABLE \"accounts\" ADD COLUMN \"email\" VARCHAR;\n"},
+		Err:       cause,
+	}
+
+	as.Eq("statement 2 of 5 (AddColumn accounts.email) failed: relation \"accounts\" does not exist\nALTER TABLE \"accounts\" ADD COLUMN \"email\" VARCHAR;", err.Error())
+	as.True(errors.Is(err, cause))
+}
@@ -0,0 +1,205 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Summary summarizes the objects a plan adds, removes and changes
+type Summary struct {
+	Schemas     SummaryGroup `json:"schemas"`
+	Enums       SummaryGroup `json:"enums"`
+	Tables      SummaryGroup `json:"tables"`
+	Columns     SummaryGroup `json:"columns"`
+	Constraints SummaryGroup `json:"constraints"`
+	Views       SummaryGroup `json:"views"`
+	Rows        SummaryGroup `json:"rows"`
+}
+
+// SummaryGroup holds the sorted names of the added, removed and changed objects of a kind
+type SummaryGroup struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Empty returns true when no object is added, removed or changed
+func (x SummaryGroup) Empty() bool {
+	return len(x.Added) == 0 && len(x.Removed) == 0 && len(x.Changed) == 0
+}
+
+// Summary returns the summary of the plan, the columns and constraints of added
+// and removed tables are part of the table and aren't listed separately
+func (x Plan) Summary() Summary {
+	schemas, enums, tables, columns := newSummarySet(), newSummarySet(), newSummarySet(), newSummarySet()
+	constraints, views, rows := newSummarySet(), newSummarySet(), newSummarySet()
+	recreated := map[string]string{}
+
+	for _, change := range x.Changes {
+		switch change.Kind {
+		case KindAddTable:
+			tables.added[change.Table] = true
+		case KindDropTable:
+			tables.removed[change.Table] = true
+		}
+	}
+
+	existing := func(table string) bool {
+		return table != `` && !tables.added[table] && !tables.removed[table]
+	}
+
+	for _, change := range x.Changes {
+		switch change.Kind {
+		case KindAddSchema:
+			schemas.added[change.Name] = true
+		case KindDropSchema:
+			schemas.removed[change.Name] = true
+		case KindAddEnum:
+			enums.added[change.Name] = true
+		case KindDropEnum:
+			enums.removed[change.Name] = true
+		case KindAddEnumValue:
+			enums.changed[change.Name] = true
+		case KindRenameEnum:
+			schema, _ := splitName(change.Name)
+			recreated[qualify(schema, change.To)] = change.Name
+		case KindAddView:
+			views.added[change.Name] = true
+		case KindDropView:
+			views.removed[change.Name] = true
+		case KindUpsertRow:
+			rows.changed[change.Table] = true
+		case KindDeleteRow:
+			rows.removed[change.Table] = true
+		case KindAddColumn:
+			addIf(existing(change.Table), columns.added, change.Object)
+		case KindDropColumn:
+			addIf(existing(change.Table), columns.removed, change.Object)
+		case KindAlterType, KindBackfill, KindSetAutoIncrement, KindDropAutoIncrement,
+			KindSetNotNull, KindDropNotNull, KindSetDefault, KindDropDefault:
+			addIf(existing(change.Table), columns.changed, change.Object)
+		case KindAddPrimaryKey, KindAddUnique, KindAddForeignKey, KindAddCheck:
+			addIf(existing(change.Table), constraints.added, constraintName(change))
+		case KindDropPrimaryKey, KindDropUnique, KindDropForeignKey, KindDropCheck:
+			addIf(existing(change.Table), constraints.removed, constraintName(change))
+		case KindAlterPrimaryKey, KindAlterUnique, KindAlterForeignKey, KindAlterCheck:
+			addIf(existing(change.Table), constraints.changed, constraintName(change))
+		default:
+			continue
+		}
+
+		if change.Kind != KindUpsertRow && change.Kind != KindDeleteRow && existing(change.Table) {
+			tables.changed[change.Table] = true
+		}
+	}
+
+	// views are dropped and created again when they change
+	for name := range views.added {
+		if views.removed[name] {
+			delete(views.added, name)
+			delete(views.removed, name)
+			views.changed[name] = true
+		}
+	}
+
+	// recreated enums are renamed, created again and the renamed type is dropped
+	for old, name := range recreated {
+		delete(enums.removed, old)
+		delete(enums.added, name)
+		enums.changed[name] = true
+	}
+
+	return Summary{
+		Schemas:     schemas.group(),
+		Enums:       enums.group(),
+		Tables:      tables.group(),
+		Columns:     columns.group(),
+		Constraints: constraints.group(),
+		Views:       views.group(),
+		Rows:        rows.group(),
+	}
+}
+
+// summarySet collects the names of a summary group
+type summarySet struct {
+	added, removed, changed map[string]bool
+}
+
+func newSummarySet() *summarySet {
+	return &summarySet{added: map[string]bool{}, removed: map[string]bool{}, changed: map[string]bool{}}
+}
+
+func (x *summarySet) group() SummaryGroup {
+	return SummaryGroup{Added: sortedSet(x.added), Removed: sortedSet(x.removed), Changed: sortedSet(x.changed)}
+}
+
+// String returns the summary as a human readable list
+func (x Summary) String() string {
+	builder := &strings.Builder{}
+	groups := []struct {
+		name  string
+		group SummaryGroup
+	}{
+		{`Schemas`, x.Schemas},
+		{`Enums`, x.Enums},
+		{`Tables`, x.Tables},
+		{`Columns`, x.Columns},
+		{`Constraints`, x.Constraints},
+		{`Views`, x.Views},
+		{`Seed rows`, x.Rows},
+	}
+
+	for _, g := range groups {
+		if g.group.Empty() {
+			continue
+		}
+
+		builder.WriteString(g.name + ":\n") // nolint: errcheck
+		for _, name := range g.group.Added {
+			builder.WriteString("  + " + name + "\n") // nolint: errcheck
+		}
+		for _, name := range g.group.Removed {
+			builder.WriteString("  - " + name + "\n") // nolint: errcheck
+		}
+		for _, name := range g.group.Changed {
+			builder.WriteString("  ~ " + name + "\n") // nolint: errcheck
+		}
+	}
+
+	if builder.Len() == 0 {
+		return "No changes\n"
+	}
+
+	return builder.String()
+}
+
+func constraintName(change Change) string {
+	switch change.Kind {
+	case KindAddPrimaryKey, KindAlterPrimaryKey, KindDropPrimaryKey:
+		return fmt.Sprintf("primary key %s", change.Table)
+	case KindAddUnique, KindAlterUnique, KindDropUnique:
+		return fmt.Sprintf("unique %s on %s", change.Name, change.Table)
+	case KindAddForeignKey, KindAlterForeignKey, KindDropForeignKey:
+		return fmt.Sprintf("foreign key %s", change.Object)
+	}
+
+	return fmt.Sprintf("check %s", change.Object)
+}
+
+// addIf adds the name to the set when the object belongs to an existing table
+func addIf(existing bool, set map[string]bool, name string) {
+	if existing {
+		set[name] = true
+	}
+}
+
+func sortedSet(set map[string]bool) []string {
+	var list []string
+	for k := range set {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+
+	return list
+}
@@ -0,0 +1,21 @@
+CREATE TYPE "status" AS ENUM ('active', 'banned');
+ALTER TABLE "accounts" ADD COLUMN "active" BOOLEAN;
+ALTER TABLE "accounts" ALTER COLUMN "active" SET DEFAULT true;
+UPDATE "accounts" SET "active" = DEFAULT WHERE "active" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "active" SET NOT NULL;
+ALTER TABLE "accounts" ADD COLUMN "name" VARCHAR;
+UPDATE "accounts" SET "name" = '' WHERE "name" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "name" SET NOT NULL;
+ALTER TABLE "accounts" ADD COLUMN "number" INT;
+CREATE SEQUENCE "seq_accounts_number" OWNED BY "accounts"."number";
+SELECT setval('"seq_accounts_number"', (SELECT max("number") FROM "accounts"));
+ALTER TABLE "accounts" ALTER COLUMN "number" SET DEFAULT nextval('"seq_accounts_number"'::regclass);
+UPDATE "accounts" SET "number" = DEFAULT WHERE "number" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "number" SET NOT NULL;
+ALTER TABLE "accounts" ADD COLUMN "parent_id" INT;
+ALTER TABLE "accounts" ALTER COLUMN "parent_id" SET NOT NULL;
+ALTER TABLE "accounts" ADD COLUMN "status" "status";
+UPDATE "accounts" SET "status" = 'active' WHERE "status" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "status" SET NOT NULL;
+ALTER TABLE "accounts" ADD CONSTRAINT "ch_accounts_status" CHECK(status<>'banned');
+ALTER TABLE "accounts" ADD CONSTRAINT "fk_accounts_parent_id" FOREIGN KEY ("parent_id") REFERENCES "accounts"("id");
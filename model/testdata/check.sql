@@ -0,0 +1,6 @@
+ALTER TABLE "products" DROP CONSTRAINT "ch_products_price";
+ALTER TABLE "products" ADD CONSTRAINT "ch_products_price" CHECK(price>=0);
+ALTER TABLE "products" ADD COLUMN "size" INT;
+ALTER TABLE "products" ADD CONSTRAINT "ch_products_size" CHECK(size<100);
+ALTER TABLE "products" DROP CONSTRAINT "ch_products_stock";
+ALTER TABLE "products" ADD CONSTRAINT "ch_products_weight" CHECK(weight>0);
@@ -0,0 +1,5 @@
+CREATE TYPE "status" AS ENUM ('active', 'banned');
+ALTER TABLE "accounts" ALTER COLUMN "age" TYPE INT USING nullif(trim(age), '')::int;
+ALTER TABLE "accounts" ALTER COLUMN "level" TYPE BIGINT;
+ALTER TABLE "accounts" ALTER COLUMN "score" TYPE INT;
+ALTER TABLE "accounts" ALTER COLUMN "status" TYPE "status" USING "status"::text::"status";
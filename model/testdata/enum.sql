@@ -0,0 +1,6 @@
+ALTER TYPE "level" RENAME TO "level_old";
+CREATE TYPE "level" AS ENUM ('high', 'low');
+ALTER TABLE "accounts" ALTER COLUMN "level" TYPE "level" USING "level"::text::"level";
+ALTER TYPE "mood" ADD VALUE IF NOT EXISTS 'content' BEFORE 'sad';
+ALTER TYPE "mood" ADD VALUE IF NOT EXISTS 'angry';
+DROP TYPE "level_old";
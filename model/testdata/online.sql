@@ -0,0 +1,14 @@
+ALTER TABLE "accounts" ADD CONSTRAINT "nn_accounts_name" CHECK("name" IS NOT NULL) NOT VALID;
+ALTER TABLE "accounts" ADD CONSTRAINT "ch_accounts_name" CHECK(name<>'') NOT VALID;
+ALTER TABLE "accounts" ADD COLUMN "role_id" INT;
+CREATE TABLE "grants"();
+ALTER TABLE "grants" ADD COLUMN "id" INT;
+ALTER TABLE "grants" ADD COLUMN "role_id" INT;
+ALTER TABLE "grants" ALTER COLUMN "role_id" SET NOT NULL;
+CREATE TABLE "posts"();
+ALTER TABLE "posts" ADD COLUMN "account_id" INT;
+ALTER TABLE "posts" ALTER COLUMN "account_id" SET NOT NULL;
+ALTER TABLE "posts" ADD COLUMN "id" INT;
+ALTER TABLE "grants" ADD CONSTRAINT "pk_grants" PRIMARY KEY("id");
+ALTER TABLE "posts" ADD CONSTRAINT "pk_posts" PRIMARY KEY("id");
+ALTER TABLE "posts" ADD CONSTRAINT "fk_posts_account_id" FOREIGN KEY ("account_id") REFERENCES "accounts"("id");
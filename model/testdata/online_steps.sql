@@ -0,0 +1,23 @@
+ALTER TABLE "accounts" VALIDATE CONSTRAINT "nn_accounts_name";
+
+ALTER TABLE "accounts" ALTER COLUMN "name" SET NOT NULL;
+
+ALTER TABLE "accounts" DROP CONSTRAINT "nn_accounts_name";
+
+ALTER TABLE "accounts" VALIDATE CONSTRAINT "ch_accounts_name";
+
+DROP INDEX CONCURRENTLY IF EXISTS "pk_roles";
+CREATE UNIQUE INDEX CONCURRENTLY "pk_roles" ON "roles" ("id");
+
+ALTER TABLE "roles" ADD CONSTRAINT "pk_roles" PRIMARY KEY USING INDEX "pk_roles";
+
+DROP INDEX CONCURRENTLY IF EXISTS "uq_email";
+CREATE UNIQUE INDEX CONCURRENTLY "uq_email" ON "accounts" ("email");
+
+ALTER TABLE "accounts" ADD CONSTRAINT "uq_email" UNIQUE USING INDEX "uq_email";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "fk_accounts_role_id" FOREIGN KEY ("role_id") REFERENCES "roles"("id") NOT VALID;
+
+ALTER TABLE "accounts" VALIDATE CONSTRAINT "fk_accounts_role_id";
+
+ALTER TABLE "grants" ADD CONSTRAINT "fk_grants_role_id" FOREIGN KEY ("role_id") REFERENCES "roles"("id");
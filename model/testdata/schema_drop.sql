@@ -0,0 +1,47 @@
+CREATE TYPE "bond_type" AS ENUM ('companion', 'fiance', 'spouce', 'friend');
+CREATE TABLE "account_roles"();
+ALTER TABLE "account_roles" ADD COLUMN "account_id" INT;
+ALTER TABLE "account_roles" ALTER COLUMN "account_id" SET NOT NULL;
+ALTER TABLE "account_roles" ADD COLUMN "id" INT;
+ALTER TABLE "account_roles" ADD COLUMN "role_id" INT;
+ALTER TABLE "account_roles" ALTER COLUMN "role_id" SET NOT NULL;
+ALTER TABLE "accounts" ADD COLUMN "created_at" TIMESTAMP;
+ALTER TABLE "accounts" ALTER COLUMN "created_at" SET DEFAULT NOW();
+ALTER TABLE "accounts" ADD COLUMN "email" VARCHAR;
+UPDATE "accounts" SET "email" = '' WHERE "email" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "email" SET NOT NULL;
+ALTER TABLE "accounts" ADD COLUMN "email_verified_at" TIMESTAMP;
+ALTER TABLE "accounts" ADD COLUMN "password" VARCHAR;
+UPDATE "accounts" SET "password" = '' WHERE "password" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "password" SET NOT NULL;
+ALTER TABLE "accounts" ADD COLUMN "username" VARCHAR(50);
+UPDATE "accounts" SET "username" = '' WHERE "username" IS NULL;
+ALTER TABLE "accounts" ALTER COLUMN "username" SET NOT NULL;
+CREATE TABLE "relationships"();
+ALTER TABLE "relationships" ADD COLUMN "account_id" INT;
+ALTER TABLE "relationships" ALTER COLUMN "account_id" SET NOT NULL;
+ALTER TABLE "relationships" ADD COLUMN "bond" "bond_type";
+ALTER TABLE "relationships" ALTER COLUMN "bond" SET DEFAULT 'friend';
+ALTER TABLE "relationships" ADD COLUMN "id" INT;
+ALTER TABLE "relationships" ADD COLUMN "relationship_id" INT;
+ALTER TABLE "relationships" ALTER COLUMN "relationship_id" SET NOT NULL;
+ALTER TABLE "relationships" ADD COLUMN "token" VARCHAR;
+ALTER TABLE "relationships" ALTER COLUMN "token" SET NOT NULL;
+ALTER TABLE "relationships" ADD COLUMN "verified_at" TIMESTAMP;
+CREATE TABLE "roles"();
+ALTER TABLE "roles" ADD COLUMN "id" INT;
+CREATE SEQUENCE "seq_roles_id" OWNED BY "roles"."id";
+SELECT setval('"seq_roles_id"', (SELECT max("id") FROM "roles"));
+ALTER TABLE "roles" ALTER COLUMN "id" SET DEFAULT nextval('"seq_roles_id"'::regclass);
+ALTER TABLE "roles" ADD COLUMN "name" VARCHAR;
+ALTER TABLE "roles" ALTER COLUMN "name" SET NOT NULL;
+DROP TABLE "billing"."invoices" CASCADE;
+ALTER TABLE "account_roles" ADD CONSTRAINT "pk_account_roles" PRIMARY KEY("id");
+ALTER TABLE "relationships" ADD CONSTRAINT "pk_relationships" PRIMARY KEY("id");
+ALTER TABLE "roles" ADD CONSTRAINT "pk_roles" PRIMARY KEY("id");
+ALTER TABLE "relationships" ADD CONSTRAINT "uq_account_relationship" UNIQUE("account_id", "relationship_id");
+ALTER TABLE "account_roles" ADD CONSTRAINT "fk_account_roles_account_id" FOREIGN KEY ("account_id") REFERENCES "accounts"("id");
+ALTER TABLE "account_roles" ADD CONSTRAINT "fk_account_roles_role_id" FOREIGN KEY ("role_id") REFERENCES "roles"("id");
+ALTER TABLE "relationships" ADD CONSTRAINT "fk_relationships_account_id" FOREIGN KEY ("account_id") REFERENCES "accounts"("id");
+ALTER TABLE "relationships" ADD CONSTRAINT "fk_relationships_relationship_id" FOREIGN KEY ("relationship_id") REFERENCES "accounts"("id");
+DROP SCHEMA "billing";
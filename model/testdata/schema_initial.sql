@@ -0,0 +1,16 @@
+CREATE SCHEMA IF NOT EXISTS "billing";
+CREATE TABLE "accounts"();
+ALTER TABLE "accounts" ADD COLUMN "id" INT;
+CREATE SEQUENCE "seq_accounts_id" OWNED BY "accounts"."id";
+SELECT setval('"seq_accounts_id"', (SELECT max("id") FROM "accounts"));
+ALTER TABLE "accounts" ALTER COLUMN "id" SET DEFAULT nextval('"seq_accounts_id"'::regclass);
+CREATE TABLE "billing"."invoices"();
+ALTER TABLE "billing"."invoices" ADD COLUMN "account_id" INT;
+ALTER TABLE "billing"."invoices" ALTER COLUMN "account_id" SET NOT NULL;
+ALTER TABLE "billing"."invoices" ADD COLUMN "id" INT;
+CREATE SEQUENCE "billing"."seq_invoices_id" OWNED BY "billing"."invoices"."id";
+SELECT setval('"billing"."seq_invoices_id"', (SELECT max("id") FROM "billing"."invoices"));
+ALTER TABLE "billing"."invoices" ALTER COLUMN "id" SET DEFAULT nextval('"billing"."seq_invoices_id"'::regclass);
+ALTER TABLE "accounts" ADD CONSTRAINT "pk_accounts" PRIMARY KEY("id");
+ALTER TABLE "billing"."invoices" ADD CONSTRAINT "pk_invoices" PRIMARY KEY("id");
+ALTER TABLE "billing"."invoices" ADD CONSTRAINT "fk_invoices_account_id" FOREIGN KEY ("account_id") REFERENCES "accounts"("id");
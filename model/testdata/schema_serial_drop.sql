@@ -0,0 +1,2 @@
+DROP TABLE "billing"."invoices" CASCADE;
+DROP SCHEMA "billing";
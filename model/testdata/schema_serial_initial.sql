@@ -0,0 +1,7 @@
+CREATE SCHEMA IF NOT EXISTS "billing";
+CREATE TABLE "billing"."invoices"();
+ALTER TABLE "billing"."invoices" ADD COLUMN "id" INT;
+CREATE SEQUENCE "billing"."seq_invoices_id" OWNED BY "billing"."invoices"."id";
+SELECT setval('"billing"."seq_invoices_id"', (SELECT max("id") FROM "billing"."invoices"));
+ALTER TABLE "billing"."invoices" ALTER COLUMN "id" SET DEFAULT nextval('"billing"."seq_invoices_id"'::regclass);
+ALTER TABLE "billing"."invoices" ADD CONSTRAINT "pk_invoices" PRIMARY KEY("id");
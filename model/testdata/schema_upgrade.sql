@@ -0,0 +1,19 @@
+CREATE SCHEMA IF NOT EXISTS "billing";
+CREATE TABLE "billing"."invoices"();
+ALTER TABLE "billing"."invoices" ADD COLUMN "account_id" INT;
+ALTER TABLE "billing"."invoices" ALTER COLUMN "account_id" SET NOT NULL;
+ALTER TABLE "billing"."invoices" ADD COLUMN "id" INT;
+CREATE SEQUENCE "billing"."seq_invoices_id" OWNED BY "billing"."invoices"."id";
+SELECT setval('"billing"."seq_invoices_id"', (SELECT max("id") FROM "billing"."invoices"));
+ALTER TABLE "billing"."invoices" ALTER COLUMN "id" SET DEFAULT nextval('"billing"."seq_invoices_id"'::regclass);
+DROP TABLE "account_roles" CASCADE;
+ALTER TABLE "accounts" DROP COLUMN "created_at";
+ALTER TABLE "accounts" DROP COLUMN "email";
+ALTER TABLE "accounts" DROP COLUMN "email_verified_at";
+ALTER TABLE "accounts" DROP COLUMN "password";
+ALTER TABLE "accounts" DROP COLUMN "username";
+DROP TABLE "relationships" CASCADE;
+DROP TABLE "roles" CASCADE;
+ALTER TABLE "billing"."invoices" ADD CONSTRAINT "pk_invoices" PRIMARY KEY("id");
+ALTER TABLE "billing"."invoices" ADD CONSTRAINT "fk_invoices_account_id" FOREIGN KEY ("account_id") REFERENCES "accounts"("id");
+DROP TYPE "bond_type";
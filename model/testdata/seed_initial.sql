@@ -0,0 +1,12 @@
+CREATE TABLE "roles"();
+ALTER TABLE "roles" ADD COLUMN "id" INT;
+CREATE SEQUENCE "seq_roles_id" OWNED BY "roles"."id";
+SELECT setval('"seq_roles_id"', (SELECT max("id") FROM "roles"));
+ALTER TABLE "roles" ALTER COLUMN "id" SET DEFAULT nextval('"seq_roles_id"'::regclass);
+ALTER TABLE "roles" ADD COLUMN "name" VARCHAR;
+ALTER TABLE "roles" ALTER COLUMN "name" SET NOT NULL;
+ALTER TABLE "roles" ADD CONSTRAINT "pk_roles" PRIMARY KEY("id");
+INSERT INTO "roles" ("id", "name") VALUES ('1', 'admin') ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name";
+INSERT INTO "roles" ("id", "name") VALUES ('2', 'user') ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name";
+INSERT INTO "roles" ("id", "name") VALUES ('3', 'guest') ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name";
+SELECT setval('"seq_roles_id"', (SELECT max("id") FROM "roles"));
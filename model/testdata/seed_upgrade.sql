@@ -0,0 +1,15 @@
+CREATE TABLE "permissions"();
+ALTER TABLE "permissions" ADD COLUMN "id" INT;
+CREATE SEQUENCE "seq_permissions_id" OWNED BY "permissions"."id";
+SELECT setval('"seq_permissions_id"', (SELECT max("id") FROM "permissions"));
+ALTER TABLE "permissions" ALTER COLUMN "id" SET DEFAULT nextval('"seq_permissions_id"'::regclass);
+ALTER TABLE "permissions" ADD COLUMN "role_id" INT;
+ALTER TABLE "permissions" ALTER COLUMN "role_id" SET NOT NULL;
+ALTER TABLE "permissions" ADD CONSTRAINT "pk_permissions" PRIMARY KEY("id");
+ALTER TABLE "permissions" ADD CONSTRAINT "fk_permissions_role_id" FOREIGN KEY ("role_id") REFERENCES "roles"("id");
+DELETE FROM "roles" WHERE "id" = '3';
+INSERT INTO "roles" ("id", "name") VALUES ('2', 'member') ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name";
+INSERT INTO "roles" ("id", "name") VALUES ('4', 'owner') ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name";
+SELECT setval('"seq_roles_id"', (SELECT max("id") FROM "roles"));
+INSERT INTO "permissions" ("id", "role_id") VALUES ('1', '4') ON CONFLICT ("id") DO UPDATE SET "role_id" = EXCLUDED."role_id";
+SELECT setval('"seq_permissions_id"', (SELECT max("id") FROM "permissions"));
@@ -0,0 +1,19 @@
+CREATE TYPE "post_type" AS ENUM ('general', 'blog');
+ALTER TABLE "accounts" ADD COLUMN "bio" TEXT;
+CREATE TABLE "posts"();
+ALTER TABLE "posts" ADD COLUMN "context" TEXT;
+ALTER TABLE "posts" ALTER COLUMN "context" SET NOT NULL;
+ALTER TABLE "posts" ADD COLUMN "created_at" TIMESTAMP;
+ALTER TABLE "posts" ALTER COLUMN "created_at" SET DEFAULT NOW();
+ALTER TABLE "posts" ADD COLUMN "created_by" INT;
+ALTER TABLE "posts" ALTER COLUMN "created_by" SET NOT NULL;
+ALTER TABLE "posts" ADD COLUMN "id" INT;
+CREATE SEQUENCE "seq_posts_id" OWNED BY "posts"."id";
+SELECT setval('"seq_posts_id"', (SELECT max("id") FROM "posts"));
+ALTER TABLE "posts" ALTER COLUMN "id" SET DEFAULT nextval('"seq_posts_id"'::regclass);
+ALTER TABLE "posts" ADD COLUMN "type" "post_type";
+ALTER TABLE "posts" ALTER COLUMN "type" SET DEFAULT 'general';
+ALTER TABLE "relationships" DROP COLUMN "bond";
+ALTER TABLE "posts" ADD CONSTRAINT "pk_posts" PRIMARY KEY("id");
+ALTER TABLE "posts" ADD CONSTRAINT "fk_posts_created_by" FOREIGN KEY ("created_by") REFERENCES "accounts"("id");
+DROP TYPE "bond_type";
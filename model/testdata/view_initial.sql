@@ -0,0 +1,11 @@
+CREATE TABLE "accounts"();
+ALTER TABLE "accounts" ADD COLUMN "id" INT;
+CREATE SEQUENCE "seq_accounts_id" OWNED BY "accounts"."id";
+SELECT setval('"seq_accounts_id"', (SELECT max("id") FROM "accounts"));
+ALTER TABLE "accounts" ALTER COLUMN "id" SET DEFAULT nextval('"seq_accounts_id"'::regclass);
+ALTER TABLE "accounts" ADD COLUMN "username" VARCHAR;
+ALTER TABLE "accounts" ALTER COLUMN "username" SET NOT NULL;
+ALTER TABLE "accounts" ADD CONSTRAINT "pk_accounts" PRIMARY KEY("id");
+CREATE VIEW "account_names" AS SELECT id, username FROM accounts;
+CREATE MATERIALIZED VIEW "named_accounts" AS SELECT id FROM account_names;
+CREATE VIEW "unrelated" AS SELECT 1 AS one;
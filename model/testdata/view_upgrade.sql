@@ -0,0 +1,5 @@
+DROP MATERIALIZED VIEW IF EXISTS "named_accounts";
+DROP VIEW IF EXISTS "account_names";
+ALTER TABLE "accounts" ALTER COLUMN "username" TYPE TEXT;
+CREATE VIEW "account_names" AS SELECT id, username FROM accounts;
+CREATE MATERIALIZED VIEW "named_accounts" AS SELECT id FROM account_names;
@@ -0,0 +1,228 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// viewKey is the key that turns a table configuration into a view
+	viewKey = `view`
+
+	// materializedKey marks a view as a materialized view
+	materializedKey = `materialized`
+)
+
+var (
+	// identifierReg matches the (schema qualified) identifiers in a query
+	identifierReg = regexp.MustCompile(`[A-Za-z_]\w*(\.[A-Za-z_]\w*)?`)
+
+	// queryReg matches the start of a query a view can be created with
+	queryReg = regexp.MustCompile(`(?i)^(\(|(select|with|values|table)\b)`)
+)
+
+// IsQuery returns true when the value of a view key is a query, otherwise the
+// key is a column of a table
+func IsQuery(value string) bool {
+	return queryReg.MatchString(strings.TrimSpace(value))
+}
+
+// ViewConfig is the configuration of a (materialized) view
+type ViewConfig struct {
+	Query        string            `yaml:"query"`
+	Materialized bool              `yaml:"materialized"`
+	Columns      map[string]string `yaml:"columns,flow"`
+}
+
+// View is a read only query stored in the database
+type View struct {
+	Name         string
+	Query        string
+	Materialized bool
+	Columns      map[string]*Column
+	// Depends contains the tables and views the view selects from
+	Depends []string
+}
+
+func appendViews(m Model, views map[string]ViewConfig) (Model, error) {
+	for name, conf := range views {
+		if _, ok := m.Tables[name]; ok {
+			return m, fmt.Errorf("view %s has the same name as a table", name)
+		}
+
+		view := new(View)
+		view.Name = name
+		view.Query = strings.TrimSuffix(strings.TrimSpace(conf.Query), `;`)
+		view.Materialized = conf.Materialized
+		view.Columns = map[string]*Column{}
+
+		if view.Query == `` {
+			return m, fmt.Errorf("view %s has no query", name)
+		}
+
+		for cname, content := range conf.Columns {
+			col := new(Column)
+			col.raw = content
+			col.Table = name
+			col.Name = cname
+			col.NotNull = notnullReg.MatchString(content)
+
+			col.rawtype, col.Size = rawtype(content)
+			if col.rawtype == `` {
+				return m, fmt.Errorf("no type found in view %s column %s", name, cname)
+			}
+
+			col.Datatype = m.lookup(name, col.rawtype)
+			if col.Datatype == nil {
+				return m, fmt.Errorf("unrecognized datatype in view %s column %s type: %s", name, cname, col.rawtype)
+			}
+
+			view.Columns[cname] = col
+		}
+
+		m.Views[name] = view
+	}
+
+	for _, view := range m.Views {
+		view.Depends = m.dependencies(view)
+	}
+
+	return m, nil
+}
+
+// dependencies returns the tables and views the given view selects from,
+// these are found by matching the identifiers in the query and the tables
+// of the columns the view columns refer to
+func (x Model) dependencies(view *View) []string {
+	deps := map[string]bool{}
+
+	schema, _ := splitName(view.Name)
+	for _, ident := range identifierReg.FindAllString(view.Query, -1) {
+		for _, name := range []string{qualify(schema, ident), ident} {
+			_, table := x.Tables[name]
+			_, other := x.Views[name]
+			if (table || other) && name != view.Name {
+				deps[name] = true
+				break
+			}
+		}
+	}
+
+	for _, col := range view.Columns {
+		if ref, ok := col.Datatype.(*Column); ok {
+			deps[ref.Table] = true
+		}
+	}
+
+	var list []string
+	for name := range deps {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// sortViews returns the views ordered so that every view comes after
+// the views it depends on
+func sortViews(views map[string]*View) []*View {
+	var names []string
+	for name := range views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sorted []*View
+	visited := map[string]bool{}
+
+	var visit func(name string)
+	visit = func(name string) {
+		view, ok := views[name]
+		if !ok || visited[name] {
+			return
+		}
+		visited[name] = true
+
+		for _, dep := range view.Depends {
+			visit(dep)
+		}
+
+		sorted = append(sorted, view)
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return sorted
+}
+
+// recreatedViews returns the views of the previous model which have to be
+// dropped before the tables are altered, this includes the views that are
+// removed, changed or depend on a table of which a column is changed or dropped
+func recreatedViews(prev, curr Model) map[string]bool {
+	altered := alteredTables(prev, curr)
+
+	drop := map[string]bool{}
+	for name, view := range prev.Views {
+		cview, ok := curr.Views[name]
+		if !ok || cview.Query != view.Query || cview.Materialized != view.Materialized {
+			drop[name] = true
+		}
+
+		for _, dep := range view.Depends {
+			if altered[dep] {
+				drop[name] = true
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for name, view := range prev.Views {
+			if drop[name] {
+				continue
+			}
+
+			for _, dep := range view.Depends {
+				if drop[dep] {
+					drop[name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return drop
+}
+
+// alteredTables returns the tables of the previous model of which the
+// table itself or a column is dropped or the column type is changed
+func alteredTables(prev, curr Model) map[string]bool {
+	altered := map[string]bool{}
+	for enum := range recreatedEnums(prev, curr) {
+		for _, col := range enumColumns(prev, curr, enum) {
+			altered[col.Table] = true
+		}
+	}
+
+	for table, cols := range prev.Tables {
+		ccols, ok := curr.Tables[table]
+		if !ok {
+			altered[table] = true
+			continue
+		}
+
+		for name, col := range cols {
+			ccol, ok := ccols[name]
+			if !ok || ccol.rawtype != col.rawtype || ccol.Size != col.Size {
+				altered[table] = true
+			}
+		}
+	}
+
+	return altered
+}
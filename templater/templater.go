@@ -2,17 +2,25 @@ package templater
 
 import (
 	_ "embed"
+	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"text/template"
 
+	"github.com/myceliums/gdb/dialect"
 	"github.com/myceliums/gdb/model"
 )
 
 //go:embed main.tmpl
 var tmpl string
 
-// WriteTemplate writes the model to the given writer
-func WriteTemplate(wr io.Writer, pkgname string, mdl model.Model) error {
+// generated are the exported names of the generated code besides the views
+var generated = []string{`Option`, `SkipMigration`, `WithDialect`, `WithLogger`, `Model`, `Open`, `Migrate`}
+
+// WriteTemplate writes the model to the given writer, the queries of the views
+// are written in the given dialect
+func WriteTemplate(wr io.Writer, pkgname string, dia dialect.Dialect, mdl model.Model) error {
 	t := template.New(`main`)
 
 	if _, err := t.Parse(tmpl); err != nil {
@@ -22,10 +30,132 @@ func WriteTemplate(wr io.Writer, pkgname string, mdl model.Model) error {
 	var p struct {
 		PkgName          string
 		RawConfiguration string
+		Views            []view
+		ImportTime       bool
 	}
 
 	p.PkgName = pkgname
 	p.RawConfiguration = string(mdl.Config())
 
+	for _, v := range mdl.Views {
+		view := newView(dia, v)
+		for _, f := range view.Fields {
+			p.ImportTime = p.ImportTime || strings.HasSuffix(f.GoType, `time.Time`)
+		}
+		p.Views = append(p.Views, view)
+	}
+	sort.Slice(p.Views, func(i, j int) bool { return p.Views[i].Name < p.Views[j].Name })
+
+	if err := checkNames(p.Views); err != nil {
+		return err
+	}
+
 	return t.Execute(wr, p)
 }
+
+// checkNames returns an error when the struct or query function of a view has the
+// go name of the generated code or of another view, which wouldn't compile
+func checkNames(views []view) error {
+	names := map[string]string{}
+	for _, name := range generated {
+		names[name] = `the generated ` + name
+	}
+
+	for _, v := range views {
+		for _, name := range []string{v.GoName, `Query` + v.GoName} {
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("view %s is generated as %s which collides with %s, rename the view", v.Name, name, other)
+			}
+			names[name] = `view ` + v.Name
+		}
+	}
+
+	return nil
+}
+
+// view is the template data of a read only view struct
+type view struct {
+	Name   string
+	GoName string
+	Fields []field
+	// Query selects the fields from the view
+	Query string
+}
+
+type field struct {
+	Name   string
+	GoName string
+	GoType string
+}
+
+func newView(dia dialect.Dialect, v *model.View) view {
+	x := view{Name: v.Name, GoName: goName(v.Name)}
+
+	for _, col := range v.Columns {
+		f := field{Name: col.Name, GoName: goName(col.Name), GoType: goType(col.Type())}
+		if !col.NotNull {
+			f.GoType = `*` + f.GoType
+		}
+		x.Fields = append(x.Fields, f)
+	}
+	sort.Slice(x.Fields, func(i, j int) bool { return x.Fields[i].Name < x.Fields[j].Name })
+
+	columns := make([]string, len(x.Fields))
+	for i, f := range x.Fields {
+		columns[i] = dia.QuoteIdentifier(f.Name)
+	}
+	x.Query = `SELECT ` + strings.Join(columns, `, `) + ` FROM ` + dia.QuoteIdentifier(v.Name)
+
+	return x
+}
+
+// goType returns the go type of the given database type, enums are strings
+func goType(typename string) string {
+	switch typename {
+	case `int`:
+		return `int`
+	case `bigint`:
+		return `int64`
+	case `smallint`:
+		return `int32`
+	case `float`:
+		return `float32`
+	case `double`:
+		return `float64`
+	case `boolean`:
+		return `bool`
+	case `timestamp`:
+		return `time.Time`
+	}
+
+	return `string`
+}
+
+// initialisms are the words that are written in capitals in go names
+var initialisms = map[string]bool{
+	`id`:   true,
+	`url`:  true,
+	`uri`:  true,
+	`api`:  true,
+	`json`: true,
+	`uuid`: true,
+	`ip`:   true,
+	`sql`:  true,
+	`html`: true,
+	`http`: true,
+}
+
+// goName returns the exported camelcase go name of the given (schema qualified) name
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '.' || r == '-' }) {
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return b.String()
+}
@@ -1 +1,107 @@
 package templater
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/myceliums/assert"
+	"github.com/myceliums/gdb/dialect"
+	"github.com/myceliums/gdb/model"
+)
+
+var postgres = dialect.GetByDriver(`postgres`)
+
+// imports type checks the imports of the generated code from their source
+var imports = importer.ForCompiler(token.NewFileSet(), `source`, nil)
+
+// compile type checks the generated code like the compiler does
+func compile(src string) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, `model.gen.go`, src, 0)
+	if err != nil {
+		return err
+	}
+
+	conf := types.Config{Importer: imports}
+	_, err = conf.Check(`dbc`, fset, []*ast.File{f}, nil)
+	return err
+}
+
+func TestWriteTemplateViews(t *testing.T) {
+	as := assert.New(t)
+
+	mdl, err := model.New([]byte(`
+accounts:
+  id: serial primary
+  username: varchar not null
+  created_at: timestamp
+
+reports:
+  account_overview:
+    view: SELECT id, username AS user, created_at FROM accounts
+    id: accounts.id not null
+    user: varchar not null
+    created_at: timestamp
+`))
+	as.NoError(err)
+
+	buf := &bytes.Buffer{}
+	as.NoError(WriteTemplate(buf, `dbc`, postgres, *mdl))
+
+	out := buf.String()
+	as.True(strings.Contains(out, `type ReportsAccountOverview struct {`), out)
+	as.True(strings.Contains(out, `ID int`), out)
+	as.True(strings.Contains(out, `CreatedAt *time.Time`), out)
+	as.True(strings.Contains(out, `func QueryReportsAccountOverview(db *sql.DB) ([]ReportsAccountOverview, error) {`), out)
+	as.True(strings.Contains(out, "db.Query(`SELECT \"created_at\", \"id\", \"user\" FROM \"reports\".\"account_overview\"`)"), out)
+
+	as.NoError(compile(out))
+}
+
+func TestWriteTemplateMigrate(t *testing.T) {
+	as := assert.New(t)
+
+	mdl, err := model.New([]byte(`
+accounts:
+  id: serial primary
+`))
+	as.NoError(err)
+
+	buf := &bytes.Buffer{}
+	as.NoError(WriteTemplate(buf, `dbc`, postgres, *mdl))
+
+	out := buf.String()
+	as.True(strings.Contains(out, `func Open(driver, cs string, opts ...Option) (*sql.DB, error) {`), out)
+	as.True(strings.Contains(out, `func Migrate(ctx context.Context, db *sql.DB, opts ...Option) error {`), out)
+	as.True(strings.Contains(out, `func Model() (*model.Model, error) {`), out)
+	as.True(strings.Contains(out, `func SkipMigration() Option {`), out)
+
+	as.NoError(compile(out))
+}
+
+func TestWriteTemplateNames(t *testing.T) {
+	as := assert.New(t)
+
+	for _, config := range []string{
+		"option:\n  view: SELECT 1 AS id\n  id: int\n",
+		"model:\n  view: SELECT 1 AS id\n  id: int\n",
+		"users:\n  view: SELECT 1 AS id\n  id: int\nquery_users:\n  view: SELECT 1 AS id\n  id: int\n",
+	} {
+		mdl, err := model.New([]byte(config))
+		as.NoError(err, config)
+		as.Error(WriteTemplate(&bytes.Buffer{}, `dbc`, postgres, *mdl), config)
+	}
+
+	mdl, err := model.New([]byte("options:\n  view: SELECT 1 AS id\n  id: int\nmigration:\n  view: SELECT 1 AS id\n  id: int\n"))
+	as.NoError(err)
+
+	buf := &bytes.Buffer{}
+	as.NoError(WriteTemplate(buf, `dbc`, postgres, *mdl))
+	as.NoError(compile(buf.String()))
+}